@@ -0,0 +1,16 @@
+package frotel
+
+import (
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// keysAndValuesToAttributes converts loosely typed key-value pairs, as taken by the log ...W functions, into attributes.
+func keysAndValuesToAttributes(keysAndValues ...interface{}) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		attrs = append(attrs, log.ToAttribute(fmt.Sprint(keysAndValues[i]), keysAndValues[i+1]))
+	}
+	return attrs
+}
@@ -0,0 +1,22 @@
+package frotel
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const checkpointLogKey = "Body.checkpoint.name"
+
+// Checkpoint marks progress of the current operation with a span event named name and a debug log line,
+// both carrying keysAndValues, so the marker can be found from either side.
+func Checkpoint(ctx context.Context, name string, keysAndValues ...interface{}) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(name, trace.WithAttributes(keysAndValuesToAttributes(keysAndValues...)...))
+
+	if log.IsDebugEnabled() {
+		fields := append([]interface{}{checkpointLogKey, name}, keysAndValues...)
+		log.LoggerFromContext(ctx).WithOptions(zap.AddCallerSkip(1)).Debugw("Checkpoint", fields...)
+	}
+}
@@ -0,0 +1,41 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	var spanId string
+	entries := captureOutput(t, func() {
+		frotel.InstrumentSpan(context.Background(), "import", func(ctx context.Context) interface{} {
+			spanId = trace.SpanContextFromContext(ctx).SpanID().String()
+			frotel.Checkpoint(ctx, "rows-parsed", "rows", 120, "file", "fares.csv")
+			return nil
+		})
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Len(t, spans[0].Events(), 1)
+	event := spans[0].Events()[0]
+	assert.Equal(t, "rows-parsed", event.Name)
+	assert.Equal(t, "rows", string(event.Attributes[0].Key))
+	assert.Equal(t, int64(120), event.Attributes[0].Value.AsInt64())
+	assert.Equal(t, "fares.csv", event.Attributes[1].Value.AsString())
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "DEBUG", entries[0][log.Level])
+	assert.Equal(t, "rows-parsed", entries[0]["Body.checkpoint.name"])
+	assert.Equal(t, spanId, entries[0][log.SpanId])
+	assert.Equal(t, float64(120), entries[0]["rows"])
+	assert.Equal(t, "fares.csv", entries[0]["file"])
+	assert.Contains(t, entries[0][log.Logger], "frotel/checkpoint_test.go:")
+}
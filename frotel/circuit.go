@@ -0,0 +1,52 @@
+package frotel
+
+import (
+	"context"
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+const (
+	circuitNameKey = attribute.Key("circuit.name")
+	circuitFromKey = attribute.Key("circuit.from")
+	circuitToKey   = attribute.Key("circuit.to")
+
+	circuitNameLogKey = "Body.circuit.name"
+	circuitFromLogKey = "Body.circuit.from"
+	circuitToLogKey   = "Body.circuit.to"
+)
+
+var circuitStates = map[string]bool{CircuitClosed: true, CircuitOpen: true, CircuitHalfOpen: true}
+
+// RecordCircuitState records the transition of the circuit breaker name between two of the Circuit... states
+// as a "circuit.transition" event on the current span and a log line, a warning when the circuit opens.
+func RecordCircuitState(ctx context.Context, name, from, to string) error {
+	if !circuitStates[from] || !circuitStates[to] {
+		return fmt.Errorf("invalid circuit state transition %q -> %q", from, to)
+	}
+	if from == to {
+		return fmt.Errorf("circuit %s already %s", name, to)
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("circuit.transition", trace.WithAttributes(
+		circuitNameKey.String(name),
+		circuitFromKey.String(from),
+		circuitToKey.String(to),
+	))
+
+	keysAndValues := []interface{}{circuitNameLogKey, name, circuitFromLogKey, from, circuitToLogKey, to}
+	if to == CircuitOpen {
+		log.WarnWCtx(ctx, "Circuit breaker state changed", keysAndValues...)
+	} else {
+		log.InfoWCtx(ctx, "Circuit breaker state changed", keysAndValues...)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRecordCircuitState(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	var err error
+	entries := captureOutput(t, func() {
+		frotel.InstrumentSpan(context.Background(), "pricing-call", func(ctx context.Context) interface{} {
+			err = frotel.RecordCircuitState(ctx, "pricing", frotel.CircuitClosed, frotel.CircuitOpen)
+			return nil
+		})
+	})
+	// THEN
+	require.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Events(), 1)
+	event := spans[0].Events()[0]
+	assert.Equal(t, "circuit.transition", event.Name)
+	assert.Equal(t, "pricing", event.Attributes[0].Value.AsString())
+	assert.Equal(t, "closed", event.Attributes[1].Value.AsString())
+	assert.Equal(t, "open", event.Attributes[2].Value.AsString())
+
+	require.Len(t, entries, 1)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+	assert.Equal(t, "pricing", entries[0]["Body.circuit.name"])
+	assert.Equal(t, "closed", entries[0]["Body.circuit.from"])
+	assert.Equal(t, "open", entries[0]["Body.circuit.to"])
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), entries[0][log.TraceId])
+}
+
+func TestRecordCircuitStateRejectsInvalidStates(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, func() {
+		assert.Error(t, frotel.RecordCircuitState(context.Background(), "pricing", "closed", "broken"))
+		assert.Error(t, frotel.RecordCircuitState(context.Background(), "pricing", "open", "open"))
+	})
+	// THEN
+	assert.Empty(t, entries)
+}
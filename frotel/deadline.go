@@ -0,0 +1,54 @@
+package frotel
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+)
+
+// SpanWithContextDeadline starts a span which records an error status with the cancellation cause when ctx is done
+// before the span ends, e.g. because its deadline expired. The caller is responsible for ending the span,
+// which also stops watching ctx.
+func SpanWithContextDeadline(ctx context.Context, spanName string) (context.Context, trace.Span) {
+	spanCtx, span := startSpan(ctx, spanName, 1)
+	if ctx.Done() == nil {
+		return spanCtx, span
+	}
+	deadlineSpan := &deadlineSpan{Span: span, ctx: ctx, ended: make(chan struct{})}
+	go deadlineSpan.watch()
+	return trace.ContextWithSpan(spanCtx, deadlineSpan), deadlineSpan
+}
+
+type deadlineSpan struct {
+	trace.Span
+	ctx        context.Context
+	ended      chan struct{}
+	endOnce    sync.Once
+	expireOnce sync.Once
+}
+
+func (s *deadlineSpan) watch() {
+	select {
+	case <-s.ctx.Done():
+		s.expire()
+	case <-s.ended:
+	}
+}
+
+func (s *deadlineSpan) expire() {
+	s.expireOnce.Do(func() {
+		s.Span.SetStatus(codes.Error, sanitizeStatusDescription(context.Cause(s.ctx).Error()))
+	})
+}
+
+func (s *deadlineSpan) End(options ...trace.SpanEndOption) {
+	s.endOnce.Do(func() {
+		close(s.ended)
+		// the watcher may not have been scheduled yet
+		if s.ctx.Err() != nil {
+			s.expire()
+		}
+	})
+	s.Span.End(options...)
+}
@@ -0,0 +1,52 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSpanWithContextDeadlineEndsNormally(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	goroutines := runtime.NumGoroutine()
+
+	// WHEN
+	_, span := frotel.SpanWithContextDeadline(ctx, "fetch-fares")
+	span.End()
+
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	// the watcher exits asynchronously, assert.Eventually would add a goroutine of its own
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > goroutines && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, goroutines, runtime.NumGoroutine())
+}
+
+func TestSpanWithContextDeadlineExpires(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// WHEN
+	spanCtx, span := frotel.SpanWithContextDeadline(ctx, "fetch-fares")
+	<-spanCtx.Done()
+	span.End()
+
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), spans[0].Status().Description)
+}
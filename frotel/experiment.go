@@ -0,0 +1,16 @@
+package frotel
+
+import (
+	"context"
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Variant records the variant assigned to the request for experiment as the experiment.<name> span attribute
+// and attaches it to the context-scoped logger, see log.FromContext.
+func Variant(ctx context.Context, experiment, variant string) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String(fmt.Sprintf("experiment.%s", experiment), variant))
+	return log.ContextWith(ctx, fmt.Sprintf("Body.experiment.%s", experiment), variant)
+}
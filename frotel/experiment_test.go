@@ -0,0 +1,28 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVariant(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	entries := captureOutput(t, func() {
+		frotel.InstrumentSpan(context.Background(), "checkout", func(ctx context.Context) interface{} {
+			ctx = frotel.Variant(ctx, "new-checkout", "B")
+			log.FromContext(ctx).Info("Checkout started")
+			return nil
+		})
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "B", attributesOf(spans[0])["experiment.new-checkout"].AsString())
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "B", entries[0]["Body.experiment.new-checkout"])
+}
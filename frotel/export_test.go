@@ -0,0 +1,14 @@
+package frotel
+
+// ResetTracer drops the cached tracer and its name so the next span is started from the current global provider.
+func ResetTracer() {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	tracer = nil
+	tracerName = defaultTracerName
+}
+
+// ResetMeter drops the cached meter so the next instruments are created from the current global provider.
+func ResetMeter() {
+	resetMeter()
+}
@@ -0,0 +1,195 @@
+package frotel
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	rpcSentMessagesKey     = attribute.Key("rpc.stream.sent_messages")
+	rpcReceivedMessagesKey = attribute.Key("rpc.stream.received_messages")
+)
+
+// StreamServerInterceptor traces streaming calls handled by the server: the span continues the trace propagated
+// by the client in the call metadata, lasts until the handler returns and counts the messages sent and received.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		ctx := propagation.TraceContext{}.Extract(ss.Context(), metadataCarrier(md))
+		ctx, span := getTracer().Start(ctx, spanNameOf(info.FullMethod),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(rpcAttributes(info.FullMethod)...))
+		defer span.End()
+
+		stream := &countingServerStream{ServerStream: ss, ctx: ctx}
+		err := handler(srv, stream)
+		span.SetAttributes(
+			rpcSentMessagesKey.Int64(stream.sent.Load()),
+			rpcReceivedMessagesKey.Int64(stream.received.Load()),
+		)
+		endStreamSpan(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor traces streaming calls made by the client: the trace context is propagated in the call
+// metadata and the span, counting the messages sent and received, ends once the stream is finished or ctx is done.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := getTracer().Start(ctx, spanNameOf(method),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(rpcAttributes(method)...))
+
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		propagation.TraceContext{}.Inject(ctx, metadataCarrier(md))
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endStreamSpan(span, err)
+			span.End()
+			return nil, err
+		}
+
+		stream := &countingClientStream{ClientStream: cs, span: span, serverStreams: desc.ServerStreams, done: make(chan struct{})}
+		go func() {
+			select {
+			case <-ctx.Done():
+				stream.finish(ctx.Err())
+			case <-stream.done:
+			}
+		}()
+		return stream, nil
+	}
+}
+
+type countingServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (s *countingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *countingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	}
+	return err
+}
+
+func (s *countingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+	}
+	return err
+}
+
+type countingClientStream struct {
+	grpc.ClientStream
+	span          trace.Span
+	serverStreams bool
+	sent          atomic.Int64
+	received      atomic.Int64
+	finishOnce    sync.Once
+	done          chan struct{}
+}
+
+func (s *countingClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	} else if !errors.Is(err, io.EOF) {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *countingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.received.Add(1)
+		if !s.serverStreams {
+			s.finish(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	default:
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *countingClientStream) finish(err error) {
+	s.finishOnce.Do(func() {
+		s.span.SetAttributes(
+			rpcSentMessagesKey.Int64(s.sent.Load()),
+			rpcReceivedMessagesKey.Int64(s.received.Load()),
+		)
+		endStreamSpan(s.span, err)
+		s.span.End()
+		close(s.done)
+	})
+}
+
+func endStreamSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, sanitizeStatusDescription(err.Error()))
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+}
+
+// spanNameOf turns the "/package.Service/Method" full method name into "package.Service/Method".
+func spanNameOf(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.RPCSystemGRPC}
+	if service, method, found := strings.Cut(spanNameOf(fullMethod), "/"); found {
+		attrs = append(attrs, semconv.RPCService(service), semconv.RPCMethod(method))
+	}
+	return attrs
+}
+
+// metadataCarrier adapts gRPC metadata to the propagation.TextMapCarrier interface.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+package frotel_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"net"
+	"testing"
+)
+
+var echoService = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Chat",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			for {
+				msg := &wrapperspb.StringValue{}
+				if err := stream.RecvMsg(msg); errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if err := stream.SendMsg(msg); err != nil {
+					return err
+				}
+			}
+		},
+	}},
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.StreamInterceptor(frotel.StreamServerInterceptor()))
+	server.RegisterService(&echoService, struct{}{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(frotel.StreamClientInterceptor()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// WHEN
+	stream, err := conn.NewStream(context.Background(), &echoService.Streams[0], "/test.Echo/Chat")
+	require.NoError(t, err)
+	for _, word := range []string{"one", "two", "three"} {
+		require.NoError(t, stream.SendMsg(wrapperspb.String(word)))
+	}
+	require.NoError(t, stream.CloseSend())
+	var received int
+	for {
+		if err := stream.RecvMsg(&wrapperspb.StringValue{}); err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+		received++
+	}
+
+	// THEN
+	assert.Equal(t, 3, received)
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	serverSpan, clientSpan := spans[0], spans[1]
+	assertStreamSpan(t, serverSpan, trace.SpanKindServer)
+	assertStreamSpan(t, clientSpan, trace.SpanKindClient)
+	assert.Equal(t, clientSpan.SpanContext().TraceID(), serverSpan.SpanContext().TraceID())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+}
+
+func assertStreamSpan(t *testing.T, span sdktrace.ReadOnlySpan, kind trace.SpanKind) {
+	assert.Equal(t, "test.Echo/Chat", span.Name())
+	assert.Equal(t, kind, span.SpanKind())
+	attrs := attributesOf(span)
+	assert.Equal(t, "test.Echo", attrs["rpc.service"].AsString())
+	assert.Equal(t, "Chat", attrs["rpc.method"].AsString())
+	assert.Equal(t, int64(3), attrs["rpc.stream.sent_messages"].AsInt64())
+	assert.Equal(t, int64(3), attrs["rpc.stream.received_messages"].AsInt64())
+}
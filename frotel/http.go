@@ -1,9 +1,13 @@
 package frotel
 
 import (
+	"context"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"strings"
 )
 
 func HttpClient(c *http.Client) *http.Client {
@@ -21,3 +25,19 @@ func HttpClient(c *http.Client) *http.Client {
 		Timeout:       c.Timeout,
 	}
 }
+
+// StartSpanFromHeaders starts a server span named name as a child of the span described by the traceparent and
+// tracestate headers, matched case-insensitively, e.g. of an API Gateway request. Without a valid traceparent
+// the span starts a new trace.
+func StartSpanFromHeaders(ctx context.Context, headers map[string]string, name string) (context.Context, trace.Span) {
+	carrier := propagation.MapCarrier{}
+	for key, value := range headers {
+		carrier[strings.ToLower(key)] = value
+	}
+	opts := []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindServer)}
+	extracted := Extract(ctx, carrier)
+	if spanContext := trace.SpanContextFromContext(extracted); !spanContext.IsValid() || !spanContext.IsRemote() {
+		opts = append(opts, trace.WithNewRoot())
+	}
+	return startSpan(extracted, name, 1, opts...)
+}
@@ -0,0 +1,55 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestStartSpanFromHeaders(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	headers := map[string]string{
+		"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"Tracestate":  "fr=booking",
+	}
+	// WHEN
+	_, span := frotel.StartSpanFromHeaders(context.Background(), headers, "GET /bookings")
+	span.End()
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /bookings", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.True(t, spans[0].Parent().IsRemote())
+	assert.Equal(t, "fr=booking", spans[0].SpanContext().TraceState().String())
+}
+
+func TestStartSpanFromHeadersWithoutTraceparent(t *testing.T) {
+	tests := map[string]map[string]string{
+		"no headers":            nil,
+		"malformed traceparent": {"traceparent": "00-not-a-trace-01"},
+	}
+	for name, headers := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			recorder := newSpanRecorder(t)
+			ctx, parent := frotel.StartSpanFromHeaders(context.Background(), nil, "outer")
+			// WHEN
+			_, span := frotel.StartSpanFromHeaders(ctx, headers, "GET /bookings")
+			span.End()
+			parent.End()
+			// THEN
+			spans := recorder.Ended()
+			require.Len(t, spans, 2)
+			assert.True(t, spans[0].SpanContext().IsValid())
+			assert.False(t, spans[0].Parent().IsValid())
+			assert.NotEqual(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		})
+	}
+}
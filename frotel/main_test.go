@@ -0,0 +1,12 @@
+package frotel_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.Init(log.NewConfiguration("INFO", "TEST-APPLICATION", "TEST-PROJECT", "TEST-PROJECT-GROUP", "1.0.0", "testPrefix"))
+	os.Exit(m.Run())
+}
@@ -0,0 +1,54 @@
+package frotel
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const (
+	queueLatencyKey    = attribute.Key("messaging.queue_latency_ms")
+	queueLatencyLogKey = "Body.messaging.queueLatencyMs"
+)
+
+// RecordQueueLatency records the time the message spent in the queue, from enqueuedAt until now, as the
+// messaging.queue_latency_ms attribute of the current span and logs it at debug level.
+// A zero enqueuedAt is ignored and one in the future, caused by clock skew between producer and consumer, counts as no wait.
+func RecordQueueLatency(ctx context.Context, enqueuedAt time.Time) time.Duration {
+	if enqueuedAt.IsZero() {
+		return 0
+	}
+	latency := time.Since(enqueuedAt)
+	if latency < 0 {
+		latency = 0
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(queueLatencyKey.Int64(latency.Milliseconds()))
+	if log.IsDebugEnabled() {
+		log.DebugW("Queue latency", queueLatencyLogKey, latency.Milliseconds())
+	}
+	return latency
+}
+
+// InjectTraceContext returns the W3C traceparent and tracestate of ctx, ready to be sent as SQS or SNS
+// message attributes so the consumer continues the trace.
+func InjectTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier
+}
+
+// ExtractTraceContext reads the W3C trace context sent along a message by InjectTraceContext, or any other
+// W3C compliant producer, from its attributes into ctx.
+func ExtractTraceContext(ctx context.Context, attrs map[string]string) context.Context {
+	return Extract(ctx, propagation.MapCarrier(attrs))
+}
+
+// LinkFromCarrier returns a link to the span whose W3C trace context attrs carry, as sent by InjectTraceContext.
+func LinkFromCarrier(attrs map[string]string) trace.Link {
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier(attrs))
+	return trace.Link{SpanContext: trace.SpanContextFromContext(ctx)}
+}
@@ -0,0 +1,117 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestRecordQueueLatency(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	enqueuedAt := time.Now().Add(-250 * time.Millisecond)
+	// WHEN
+	var latency time.Duration
+	frotel.InstrumentSpan(context.Background(), "consume", func(ctx context.Context) interface{} {
+		latency = frotel.RecordQueueLatency(ctx, enqueuedAt)
+		return nil
+	})
+	// THEN
+	assert.GreaterOrEqual(t, latency, 250*time.Millisecond)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, latency.Milliseconds(), attributesOf(spans[0])["messaging.queue_latency_ms"].AsInt64())
+}
+
+func TestRecordQueueLatencyHandlesFutureAndZeroTimestamps(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	var future, zero time.Duration
+	frotel.InstrumentSpan(context.Background(), "future", func(ctx context.Context) interface{} {
+		future = frotel.RecordQueueLatency(ctx, time.Now().Add(time.Minute))
+		return nil
+	})
+	frotel.InstrumentSpan(context.Background(), "zero", func(ctx context.Context) interface{} {
+		zero = frotel.RecordQueueLatency(ctx, time.Time{})
+		return nil
+	})
+	// THEN
+	assert.Zero(t, future)
+	assert.Zero(t, zero)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, int64(0), attributesOf(spans[0])["messaging.queue_latency_ms"].AsInt64())
+	assert.NotContains(t, attributesOf(spans[1]), attribute.Key("messaging.queue_latency_ms"))
+}
+
+func TestTraceContextRoundTrip(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	var attrs map[string]string
+	frotel.InstrumentSpan(context.Background(), "publish", func(ctx context.Context) interface{} {
+		attrs = frotel.InjectTraceContext(ctx)
+		return nil
+	})
+	// WHEN
+	frotel.InstrumentSpan(frotel.ExtractTraceContext(context.Background(), attrs), "consume", func(ctx context.Context) interface{} {
+		return nil
+	})
+	// THEN
+	assert.Contains(t, attrs, "traceparent")
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	producer, consumer := spans[0], spans[1]
+	assert.Equal(t, producer.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	assert.Equal(t, producer.SpanContext().SpanID(), consumer.Parent().SpanID())
+	assert.True(t, consumer.Parent().IsRemote())
+}
+
+func TestExtractTraceContextFromForeignProducer(t *testing.T) {
+	// GIVEN
+	attrs := map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"tracestate":  "vendor=value",
+	}
+	// WHEN
+	ctx := frotel.ExtractTraceContext(context.Background(), attrs)
+	// THEN
+	spanContext := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spanContext.SpanID().String())
+	assert.Equal(t, "vendor=value", spanContext.TraceState().String())
+	assert.Equal(t, attrs, frotel.InjectTraceContext(ctx))
+}
+
+func TestInjectTraceContextWithoutSpan(t *testing.T) {
+	assert.Empty(t, frotel.InjectTraceContext(context.Background()))
+}
+
+func TestInstrumentSpanWithLinks(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	batch := []map[string]string{
+		{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+	}
+	links := make([]trace.Link, 0, len(batch))
+	for _, attrs := range batch {
+		links = append(links, frotel.LinkFromCarrier(attrs))
+	}
+	// WHEN
+	frotel.InstrumentSpanWithLinks(context.Background(), "process-batch", links, func(ctx context.Context) interface{} {
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Len(t, spans[0].Links(), 2)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].Links()[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Links()[0].SpanContext.SpanID().String())
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", spans[0].Links()[1].SpanContext.TraceID().String())
+	assert.NotEqual(t, spans[0].Links()[0].SpanContext.TraceID(), spans[0].SpanContext().TraceID())
+}
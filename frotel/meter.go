@@ -0,0 +1,88 @@
+package frotel
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"sync"
+)
+
+const metricValueKey = attribute.Key("metric.value")
+
+var (
+	meterMu    sync.Mutex
+	meter      metric.Meter
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+)
+
+// getMeter returns the meter of the global provider named after the frotel tracer, meterMu has to be held.
+func getMeter() metric.Meter {
+	if meter == nil {
+		meter = otel.GetMeterProvider().Meter(getTracerName())
+		counters = map[string]metric.Int64Counter{}
+		histograms = map[string]metric.Float64Histogram{}
+	}
+	return meter
+}
+
+// resetMeter drops the meter and its instruments, so the next ones are created with the current tracer name.
+func resetMeter() {
+	meterMu.Lock()
+	defer meterMu.Unlock()
+	meter = nil
+	counters, histograms = nil, nil
+}
+
+// RecordCounter adds value to the counter name, e.g. orders.placed, and marks the current span with a name event
+// carrying value and attrs, so business metrics show up in the trace of the request too.
+func RecordCounter(ctx context.Context, name string, value int64, attrs ...attribute.KeyValue) {
+	counter, ok := getCounter(name)
+	if !ok {
+		return
+	}
+	counter.Add(ctx, value, metric.WithAttributes(attrs...))
+	AddSpanEvent(ctx, name, withMetricValue(attrs, metricValueKey.Int64(value))...)
+}
+
+// getCounter returns the counter name of the current meter, creating it on first use.
+func getCounter(name string) (metric.Int64Counter, bool) {
+	meterMu.Lock()
+	defer meterMu.Unlock()
+	if counter, ok := counters[name]; ok {
+		return counter, true
+	}
+	counter, err := getMeter().Int64Counter(name)
+	if err != nil {
+		log.Error("Error creating %s counter: %v", name, err)
+		return nil, false
+	}
+	counters[name] = counter
+	return counter, true
+}
+
+// RecordHistogram records value in the histogram name, e.g. payment.amount, marking the current span as RecordCounter does.
+func RecordHistogram(ctx context.Context, name string, value float64, attrs ...attribute.KeyValue) {
+	meterMu.Lock()
+	histogram, ok := histograms[name]
+	if !ok {
+		var err error
+		if histogram, err = getMeter().Float64Histogram(name); err != nil {
+			meterMu.Unlock()
+			log.Error("Error creating %s histogram: %v", name, err)
+			return
+		}
+		histograms[name] = histogram
+	}
+	meterMu.Unlock()
+
+	histogram.Record(ctx, value, metric.WithAttributes(attrs...))
+	AddSpanEvent(ctx, name, withMetricValue(attrs, metricValueKey.Float64(value))...)
+}
+
+// withMetricValue returns attrs followed by value, leaving the array of attrs, which may be the caller's, untouched.
+func withMetricValue(attrs []attribute.KeyValue, value attribute.KeyValue) []attribute.KeyValue {
+	return append(append(make([]attribute.KeyValue, 0, len(attrs)+1), attrs...), value)
+}
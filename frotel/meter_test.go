@@ -0,0 +1,113 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"testing"
+)
+
+func newMetricReader(t *testing.T) *metric.ManualReader {
+	reader := metric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(metric.NewMeterProvider(metric.WithReader(reader)))
+	frotel.ResetMeter()
+	t.Cleanup(func() {
+		otel.SetMeterProvider(previous)
+		frotel.ResetMeter()
+	})
+	return reader
+}
+
+func collectMetrics(t *testing.T, reader *metric.ManualReader) map[string]metricdata.Metrics {
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+	metrics := map[string]metricdata.Metrics{}
+	for _, scope := range collected.ScopeMetrics {
+		assert.Equal(t, "fr-otel-tracer", scope.Scope.Name)
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
+
+func TestRecordCounter(t *testing.T) {
+	// GIVEN
+	reader := newMetricReader(t)
+	recorder := newSpanRecorder(t)
+	market := attribute.String("market", "IE")
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "checkout", func(ctx context.Context) interface{} {
+		frotel.RecordCounter(ctx, "orders.placed", 1, market)
+		frotel.RecordCounter(ctx, "orders.placed", 2, market)
+		return nil
+	})
+	// THEN
+	metrics := collectMetrics(t, reader)
+	sum, ok := metrics["orders.placed"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(3), sum.DataPoints[0].Value)
+	value, _ := sum.DataPoints[0].Attributes.Value("market")
+	assert.Equal(t, "IE", value.AsString())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Events(), 2)
+	assert.Equal(t, "orders.placed", spans[0].Events()[1].Name)
+	assert.Contains(t, spans[0].Events()[1].Attributes, attribute.Int64("metric.value", 2))
+}
+
+func TestRecordHistogram(t *testing.T) {
+	// GIVEN
+	reader := newMetricReader(t)
+	// WHEN
+	frotel.RecordHistogram(context.Background(), "payment.amount", 19.5)
+	frotel.RecordHistogram(context.Background(), "payment.amount", 30.5)
+	// THEN
+	metrics := collectMetrics(t, reader)
+	histogram, ok := metrics["payment.amount"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, uint64(2), histogram.DataPoints[0].Count)
+	assert.Equal(t, 50.0, histogram.DataPoints[0].Sum)
+}
+
+func TestRecordCounterAfterProviderSwap(t *testing.T) {
+	// GIVEN
+	previous := newMetricReader(t)
+	frotel.RecordCounter(context.Background(), "orders.placed", 1)
+	reader := newMetricReader(t)
+	// WHEN
+	frotel.RecordCounter(context.Background(), "orders.placed", 2)
+	// THEN
+	sum, ok := collectMetrics(t, reader)["orders.placed"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(2), sum.DataPoints[0].Value)
+	sum, ok = collectMetrics(t, previous)["orders.placed"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+}
+
+func TestRecordCounterLeavesAttributesUntouched(t *testing.T) {
+	// GIVEN
+	_ = newMetricReader(t)
+	recorder := newSpanRecorder(t)
+	attrs := make([]attribute.KeyValue, 1, 2)
+	attrs[0] = attribute.String("market", "IE")
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "checkout", func(ctx context.Context) interface{} {
+		frotel.RecordCounter(ctx, "orders.placed", 1, attrs...)
+		return nil
+	})
+	// THEN
+	assert.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, attribute.KeyValue{}, attrs[:2][1])
+}
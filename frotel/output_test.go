@@ -0,0 +1,38 @@
+package frotel_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+// captureOutput initialises the logger at DEBUG, runs fn and returns the decoded JSON entries it wrote.
+func captureOutput(t *testing.T, fn func()) []map[string]interface{} {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "log")
+	require.NoError(t, err)
+	defer file.Close()
+
+	stderr := os.Stderr
+	os.Stderr = file
+	log.Init(log.NewConfiguration("DEBUG", "TEST-APPLICATION", "TEST-PROJECT", "TEST-PROJECT-GROUP", "1.0.0", "testPrefix"))
+	os.Stderr = stderr
+
+	fn()
+	_ = log.Flush()
+
+	_, err = file.Seek(0, 0)
+	require.NoError(t, err)
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry), scanner.Text())
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
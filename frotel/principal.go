@@ -0,0 +1,42 @@
+package frotel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/Ryanair/gofrlib/log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"sync/atomic"
+)
+
+const (
+	enduserIdLogKey   = "Body.enduser.id"
+	enduserRoleLogKey = "Body.enduser.role"
+)
+
+var redactPrincipalIds atomic.Bool
+
+// RedactPrincipalIDs makes SetPrincipal record a SHA-256 based pseudonym instead of the plain user id,
+// which still correlates requests of the same user without exposing who it is.
+func RedactPrincipalIDs(enabled bool) {
+	redactPrincipalIds.Store(enabled)
+}
+
+// SetPrincipal records the user the request is made by as the enduser.id and enduser.role attributes of the current span
+// and attaches them to the context-scoped logger, see log.FromContext.
+func SetPrincipal(ctx context.Context, userID, role string) context.Context {
+	if redactPrincipalIds.Load() {
+		userID = pseudonymize(userID)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		semconv.EnduserID(userID),
+		semconv.EnduserRole(role),
+	)
+	return log.ContextWith(ctx, enduserIdLogKey, userID, enduserRoleLogKey, role)
+}
+
+func pseudonymize(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,70 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestSetPrincipal(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	entries := captureOutput(t, func() {
+		frotel.InstrumentSpan(context.Background(), "change-booking", func(ctx context.Context) interface{} {
+			ctx = frotel.SetPrincipal(ctx, "user-42", "agent")
+			log.FromContext(ctx).Info("Booking changed")
+			return nil
+		})
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	attrs := attributesOf(spans[0])
+	assert.Equal(t, "user-42", attrs["enduser.id"].AsString())
+	assert.Equal(t, "agent", attrs["enduser.role"].AsString())
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "user-42", entries[0]["Body.enduser.id"])
+	assert.Equal(t, "agent", entries[0]["Body.enduser.role"])
+}
+
+func TestSetPrincipalRedactsUserId(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	frotel.RedactPrincipalIDs(true)
+	defer frotel.RedactPrincipalIDs(false)
+	// WHEN
+	entries := captureOutput(t, func() {
+		frotel.InstrumentSpan(context.Background(), "change-booking", func(ctx context.Context) interface{} {
+			ctx = frotel.SetPrincipal(ctx, "user-42", "agent")
+			log.FromContext(ctx).Info("Booking changed")
+			return nil
+		})
+	})
+	// THEN
+	id := attributesOf(recorder.Ended()[0])["enduser.id"].AsString()
+	assert.NotEqual(t, "user-42", id)
+	assert.Len(t, id, 16)
+	assert.Equal(t, id, entries[0]["Body.enduser.id"])
+}
+
+func TestRedactPrincipalIDsWhileSettingPrincipals(t *testing.T) {
+	// GIVEN
+	defer frotel.RedactPrincipalIDs(false)
+	var wg sync.WaitGroup
+	// WHEN
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(enabled bool) {
+			defer wg.Done()
+			frotel.RedactPrincipalIDs(enabled)
+			ctx := frotel.SetPrincipal(context.Background(), "user-42", "agent")
+			// THEN
+			assert.NotNil(t, ctx)
+		}(i%2 == 0)
+	}
+	wg.Wait()
+}
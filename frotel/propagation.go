@@ -0,0 +1,42 @@
+package frotel
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"sync/atomic"
+)
+
+const propagationFailuresMetric = "otel.propagation.failures"
+
+var reportPropagationFailures atomic.Bool
+
+// ReportPropagationFailures makes Extract log a warning and increment the otel.propagation.failures counter
+// whenever the carrier holds trace context headers which don't yield a valid span context.
+func ReportPropagationFailures(enabled bool) {
+	reportPropagationFailures.Store(enabled)
+}
+
+// Extract reads the W3C trace context from carrier into ctx, the same way instrumented handlers do.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	propagator := propagation.TraceContext{}
+	extracted := propagator.Extract(ctx, carrier)
+	if !reportPropagationFailures.Load() {
+		return extracted
+	}
+
+	if spanContext := trace.SpanContextFromContext(extracted); spanContext.IsValid() && spanContext.IsRemote() {
+		return extracted
+	}
+	for _, field := range propagator.Fields() {
+		if value := carrier.Get(field); value != "" {
+			log.WarnW("Trace context propagation failed", "Body.propagation."+field, value)
+			if counter, ok := getCounter(propagationFailuresMetric); ok {
+				counter.Add(ctx, 1)
+			}
+			break
+		}
+	}
+	return extracted
+}
@@ -0,0 +1,82 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"testing"
+)
+
+func TestExtractReportsMalformedCarrier(t *testing.T) {
+	// GIVEN
+	reader := newMetricReader(t)
+	frotel.ReportPropagationFailures(true)
+	defer frotel.ReportPropagationFailures(false)
+	carrier := propagation.MapCarrier{"traceparent": "00-not-a-trace-01"}
+	// WHEN
+	var ctx context.Context
+	entries := captureOutput(t, func() {
+		ctx = frotel.Extract(context.Background(), carrier)
+	})
+	// THEN
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+	assert.Equal(t, "00-not-a-trace-01", entries[0]["Body.propagation.traceparent"])
+
+	var metrics metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &metrics))
+	require.Len(t, metrics.ScopeMetrics, 1)
+	require.Len(t, metrics.ScopeMetrics[0].Metrics, 1)
+	assert.Equal(t, "otel.propagation.failures", metrics.ScopeMetrics[0].Metrics[0].Name)
+	sum := metrics.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+}
+
+func TestExtractIgnoresValidAndEmptyCarriers(t *testing.T) {
+	// GIVEN
+	frotel.ReportPropagationFailures(true)
+	defer frotel.ReportPropagationFailures(false)
+	// WHEN
+	var valid, empty context.Context
+	entries := captureOutput(t, func() {
+		valid = frotel.Extract(context.Background(), propagation.MapCarrier{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		})
+		empty = frotel.Extract(context.Background(), propagation.MapCarrier{"content-type": "application/json"})
+	})
+	// THEN
+	assert.Empty(t, entries)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", trace.SpanContextFromContext(valid).TraceID().String())
+	assert.False(t, trace.SpanContextFromContext(empty).IsValid())
+}
+
+func TestExtractReportsConcurrentFailures(t *testing.T) {
+	// GIVEN
+	reader := newMetricReader(t)
+	frotel.ReportPropagationFailures(true)
+	defer frotel.ReportPropagationFailures(false)
+	carrier := propagation.MapCarrier{"traceparent": "00-not-a-trace-01"}
+	// WHEN
+	_ = captureOutput(t, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				frotel.Extract(context.Background(), carrier)
+			}()
+		}
+		wg.Wait()
+	})
+	// THEN
+	sum, ok := collectMetrics(t, reader)["otel.propagation.failures"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.Equal(t, int64(10), sum.DataPoints[0].Value)
+}
@@ -0,0 +1,110 @@
+package frotel
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"math/rand"
+	"time"
+)
+
+const (
+	attemptKey   = attribute.Key("attempt")
+	backoffMsKey = attribute.Key("backoff_ms")
+)
+
+// BackoffConfig describes an exponential backoff: the wait before the n-th retry is
+// InitialInterval * Multiplier^(n-1), capped by MaxInterval and spread by ±Jitter of itself.
+type BackoffConfig struct {
+	// MaxAttempts is the total number of calls including the first one, values below 1 mean a single call.
+	MaxAttempts     int
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between attempts, 0 means no cap.
+	MaxInterval time.Duration
+	// Multiplier grows the wait between consecutive attempts, values below 1 keep it constant.
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, by which each wait is randomly shortened or extended.
+	Jitter float64
+}
+
+// DefaultBackoffConfig makes three attempts, waiting around 100ms and 200ms in between.
+func DefaultBackoffConfig() BackoffConfig {
+	return BackoffConfig{
+		MaxAttempts:     3,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+	}
+}
+
+// RetryWithBackoff calls fn until it succeeds, cfg.MaxAttempts is reached or ctx is done, waiting with exponential
+// backoff between attempts. Each attempt gets its own span named name with the attempt number and the backoff_ms
+// waited before it, and each retry is logged. It returns the result and error of the last attempt.
+func RetryWithBackoff[T any](ctx context.Context, name string, cfg BackoffConfig, fn func(ctx context.Context, attempt int) (T, error)) (T, error) {
+	var wait time.Duration
+	backoff := capBackoff(cfg.InitialInterval, cfg)
+	for attempt := 1; ; attempt++ {
+		result, err := runAttempt(ctx, name, attempt, wait, fn)
+		if err == nil || attempt >= cfg.MaxAttempts {
+			return result, err
+		}
+
+		wait = jitter(backoff, cfg.Jitter)
+		log.WarnW("Retrying after failed attempt",
+			"Body.retry.name", name,
+			"Body.retry.attempt", attempt,
+			"Body.retry.backoffMs", wait.Milliseconds(),
+			log.ErrorMessage, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = nextBackoff(backoff, cfg)
+	}
+}
+
+func runAttempt[T any](ctx context.Context, name string, attempt int, wait time.Duration, fn func(ctx context.Context, attempt int) (T, error)) (T, error) {
+	attemptCtx, span := getTracer().Start(ctx, name, trace.WithAttributes(
+		attemptKey.Int(attempt),
+		backoffMsKey.Int64(wait.Milliseconds()),
+	))
+	defer span.End()
+
+	result, err := fn(attemptCtx, attempt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, sanitizeStatusDescription(err.Error()))
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return result, err
+}
+
+func nextBackoff(backoff time.Duration, cfg BackoffConfig) time.Duration {
+	if cfg.Multiplier > 1 {
+		backoff = time.Duration(float64(backoff) * cfg.Multiplier)
+	}
+	return capBackoff(backoff, cfg)
+}
+
+func capBackoff(backoff time.Duration, cfg BackoffConfig) time.Duration {
+	if cfg.MaxInterval > 0 && backoff > cfg.MaxInterval {
+		backoff = cfg.MaxInterval
+	}
+	return backoff
+}
+
+func jitter(backoff time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return backoff
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return time.Duration(float64(backoff) * (1 - fraction + 2*fraction*rand.Float64()))
+}
@@ -0,0 +1,68 @@
+package frotel_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"testing"
+	"time"
+)
+
+func TestRetryWithBackoff(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	cfg := frotel.BackoffConfig{MaxAttempts: 5, InitialInterval: 10 * time.Millisecond, Multiplier: 2}
+	// WHEN
+	result, err := frotel.RetryWithBackoff(context.Background(), "fetch-fares", cfg, func(ctx context.Context, attempt int) (string, error) {
+		if attempt < 3 {
+			return "", errors.New("unavailable")
+		}
+		return "fares", nil
+	})
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "fares", result)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	for i, backoffMs := range []int64{0, 10, 20} {
+		attrs := attributesOf(spans[i])
+		assert.Equal(t, "fetch-fares", spans[i].Name())
+		assert.Equal(t, int64(i+1), attrs["attempt"].AsInt64())
+		assert.Equal(t, backoffMs, attrs["backoff_ms"].AsInt64())
+	}
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, codes.Ok, spans[2].Status().Code)
+	assert.GreaterOrEqual(t, spans[2].StartTime().Sub(spans[1].EndTime()), 20*time.Millisecond)
+}
+
+func TestRetryWithBackoffReturnsLastError(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	cfg := frotel.BackoffConfig{MaxAttempts: 2, InitialInterval: time.Millisecond, Jitter: 0.5}
+	// WHEN
+	_, err := frotel.RetryWithBackoff(context.Background(), "fetch-fares", cfg, func(ctx context.Context, attempt int) (int, error) {
+		return 0, errors.New("unavailable")
+	})
+	// THEN
+	assert.EqualError(t, err, "unavailable")
+	assert.Len(t, recorder.Ended(), 2)
+}
+
+func TestRetryWithBackoffCapsInitialInterval(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	cfg := frotel.BackoffConfig{MaxAttempts: 3, InitialInterval: time.Hour, MaxInterval: 5 * time.Millisecond, Multiplier: 2}
+	// WHEN
+	_, err := frotel.RetryWithBackoff(context.Background(), "fetch-fares", cfg, func(ctx context.Context, attempt int) (int, error) {
+		return 0, errors.New("unavailable")
+	})
+	// THEN
+	assert.EqualError(t, err, "unavailable")
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	for i, backoffMs := range []int64{0, 5, 5} {
+		assert.Equal(t, backoffMs, attributesOf(spans[i])["backoff_ms"].AsInt64())
+	}
+}
@@ -0,0 +1,21 @@
+package frotel
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-xray-sdk-go/header"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// IsSampled reports whether the trace of ctx is sampled, reading the span context or, failing that, the sampling
+// decision of the X-Ray header unless X-Ray is disabled, the same way log.SetupTraceIds does. Meant to skip
+// expensive instrumentation of requests whose spans are dropped anyway.
+func IsSampled(ctx context.Context) bool {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		return spanContext.IsSampled()
+	}
+	if traceHeader := log.TraceHeaderFromContext(ctx); traceHeader != nil {
+		return traceHeader.SamplingDecision == header.Sampled
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func contextWithTraceFlags(flags trace.TraceFlags) context.Context {
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: flags,
+	}))
+}
+
+func TestIsSampled(t *testing.T) {
+	tests := map[string]struct {
+		ctx      context.Context
+		expected bool
+	}{
+		"sampled span":     {ctx: contextWithTraceFlags(trace.FlagsSampled), expected: true},
+		"not sampled span": {ctx: contextWithTraceFlags(0), expected: false},
+		"sampled X-Ray header": {
+			ctx:      context.WithValue(context.Background(), xray.LambdaTraceHeaderKey, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"),
+			expected: true,
+		},
+		"not sampled X-Ray header": {
+			ctx:      context.WithValue(context.Background(), xray.LambdaTraceHeaderKey, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0"),
+			expected: false,
+		},
+		"no trace": {ctx: context.Background(), expected: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, frotel.IsSampled(test.ctx))
+		})
+	}
+}
+
+func TestIsSampledIgnoresHeaderWithXRayDisabled(t *testing.T) {
+	// GIVEN
+	log.Init(log.NewConfiguration("INFO", "TEST-APPLICATION", "TEST-PROJECT", "TEST-PROJECT-GROUP", "1.0.0", "testPrefix").WithXRay(false))
+	defer log.Init(log.NewConfiguration("INFO", "TEST-APPLICATION", "TEST-PROJECT", "TEST-PROJECT-GROUP", "1.0.0", "testPrefix"))
+	ctx := context.WithValue(context.Background(), xray.LambdaTraceHeaderKey, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	// WHEN
+	sampled := frotel.IsSampled(ctx)
+	// THEN
+	assert.False(t, sampled)
+	assert.True(t, frotel.IsSampled(contextWithTraceFlags(trace.FlagsSampled)))
+}
@@ -0,0 +1,54 @@
+package frotel
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+)
+
+var (
+	sourceLocationEnabled atomic.Bool
+	sourceLocationSkip    atomic.Int32
+)
+
+// RecordSourceLocation makes InstrumentSpan and InstrumentSpanWithErr record the code.function, code.filepath and
+// code.lineno attributes of the code starting the span. skip ascends that many additional frames, so helpers
+// wrapping the instrument functions can report their own callers. Resolving the caller walks the stack on every span,
+// so it's disabled by default.
+func RecordSourceLocation(enabled bool, skip int) {
+	sourceLocationSkip.Store(int32(skip))
+	sourceLocationEnabled.Store(enabled)
+}
+
+// functionAttributes describes fn, for the spans started on behalf of a function rather than by their caller.
+func functionAttributes(fn interface{}) []attribute.KeyValue {
+	pc := reflect.ValueOf(fn).Pointer()
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return nil
+	}
+	file, line := f.FileLine(pc)
+	return []attribute.KeyValue{
+		semconv.CodeFilepath(file),
+		semconv.CodeLineNumber(line),
+		semconv.CodeFunction(f.Name()),
+	}
+}
+
+// callerAttributes describes the code calling the function which calls callerAttributes, ascending skip more frames.
+func callerAttributes(skip int) []attribute.KeyValue {
+	pc, file, line, ok := runtime.Caller(skip + 2)
+	if !ok {
+		return nil
+	}
+	attrs := []attribute.KeyValue{
+		semconv.CodeFilepath(file),
+		semconv.CodeLineNumber(line),
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		attrs = append(attrs, semconv.CodeFunction(fn.Name()))
+	}
+	return attrs
+}
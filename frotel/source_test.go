@@ -0,0 +1,75 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestInstrumentSpanRecordsSourceLocation(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	frotel.RecordSourceLocation(true, 0)
+	defer frotel.RecordSourceLocation(false, 0)
+	// WHEN
+	_, file, line, _ := runtime.Caller(0)
+	frotel.InstrumentSpan(context.Background(), "located", func(ctx context.Context) interface{} { return nil })
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	attrs := attributesOf(spans[0])
+	assert.Equal(t, "github.com/Ryanair/gofrlib/frotel_test.TestInstrumentSpanRecordsSourceLocation", attrs["code.function"].AsString())
+	assert.Equal(t, file, attrs["code.filepath"].AsString())
+	assert.Equal(t, int64(line+1), attrs["code.lineno"].AsInt64())
+}
+
+func TestInstrumentSpanWithErrRecordsSourceLocationOfWrapperCaller(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	frotel.RecordSourceLocation(true, 1)
+	defer frotel.RecordSourceLocation(false, 0)
+	wrapper := func(ctx context.Context) {
+		_, _ = frotel.InstrumentSpanWithErr(ctx, "wrapped", func(ctx context.Context) (interface{}, error) { return nil, nil })
+	}
+	// WHEN
+	_, _, line, _ := runtime.Caller(0)
+	wrapper(context.Background())
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, int64(line+1), attributesOf(spans[0])["code.lineno"].AsInt64())
+}
+
+func TestInstrumentSpanSkipsSourceLocationByDefault(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "plain", func(ctx context.Context) interface{} { return nil })
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.NotContains(t, attributesOf(spans[0]), attribute.Key("code.function"))
+}
+
+func TestRecordSourceLocationWhileStartingSpans(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	defer frotel.RecordSourceLocation(false, 0)
+	var wg sync.WaitGroup
+	// WHEN
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(enabled bool) {
+			defer wg.Done()
+			frotel.RecordSourceLocation(enabled, 0)
+			frotel.InstrumentSpan(context.Background(), "concurrent", func(ctx context.Context) interface{} { return nil })
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	// THEN
+	assert.Len(t, recorder.Ended(), 8)
+}
@@ -2,13 +2,88 @@ package frotel
 
 import (
 	"context"
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
 )
 
-var tracer trace.Tracer
+const (
+	childCountKey    = attribute.Key("child.count")
+	totalDurationKey = attribute.Key("total.duration_ms")
+
+	payloadRequestBytesKey  = attribute.Key("payload.request_bytes")
+	payloadResponseBytesKey = attribute.Key("payload.response_bytes")
+
+	timedOutKey = attribute.Key("timed_out")
+	canceledKey = attribute.Key("canceled")
+)
+
+const defaultTracerName = "fr-otel-tracer"
+
+var (
+	tracerMu   sync.RWMutex
+	tracer     trace.Tracer
+	tracerName = defaultTracerName
+)
+
+func getTracer() trace.Tracer {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+	if t != nil {
+		return t
+	}
+
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	if tracer == nil {
+		tracer = otel.GetTracerProvider().Tracer(tracerName)
+	}
+	return tracer
+}
+
+// InitTracer sets the instrumentation name of the tracer used by frotel, "fr-otel-tracer" unless set.
+// Meant to be called at startup, after the global tracer provider is set.
+func InitTracer(name string) {
+	SetTracerName(name)
+}
+
+// SetTracerName replaces the "fr-otel-tracer" tracer used by frotel with one named name, obtained from the global
+// provider with opts, e.g. trace.WithInstrumentationVersion and trace.WithSchemaURL describing the instrumentation scope.
+func SetTracerName(name string, opts ...trace.TracerOption) {
+	t := otel.GetTracerProvider().Tracer(name, opts...)
+	tracerMu.Lock()
+	tracer = t
+	tracerName = name
+	tracerMu.Unlock()
+	resetMeter()
+}
+
+func getTracerName() string {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return tracerName
+}
+
+// StartSpan starts a span with the frotel tracer, the caller is responsible for ending it.
+func StartSpan(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return getTracer().Start(ctx, spanName, opts...)
+}
+
+// startSpan starts a span for the instrument function calling it, frames is the number of frames between
+// startSpan and the code the source location is reported for. A source location set by opts takes precedence.
+func startSpan(ctx context.Context, spanName string, frames int, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if sourceLocationEnabled.Load() {
+		caller := trace.WithAttributes(callerAttributes(frames + int(sourceLocationSkip.Load()))...)
+		opts = append([]trace.SpanStartOption{caller}, opts...)
+	}
+	return getTracer().Start(ctx, spanName, opts...)
+}
 
 // AddToCurrentSpan OpenTelemetry instructions https://opentelemetry.io/docs/instrumentation/go/manual/
 func AddToCurrentSpan(ctx context.Context, kv ...attribute.KeyValue) {
@@ -16,9 +91,58 @@ func AddToCurrentSpan(ctx context.Context, kv ...attribute.KeyValue) {
 	span.SetAttributes(kv...)
 }
 
+// SetSpanName renames the current span, e.g. to the route a handler resolves once it has decoded the payload.
+func SetSpanName(ctx context.Context, name string) {
+	span := trace.SpanFromContext(ctx)
+	span.SetName(name)
+}
+
+// AddSpanEvent marks a discrete moment of the current span, e.g. a cache miss, with an event named name.
+func AddSpanEvent(ctx context.Context, name string, kv ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(name, trace.WithAttributes(kv...))
+}
+
+// AddMapToCurrentSpan adds the entries of m, e.g. decoded from JSON, to the current span. Strings, bools, ints,
+// floats and slices of them keep their type, the values of any other type are stringified.
+func AddMapToCurrentSpan(ctx context.Context, m map[string]interface{}) {
+	attrs := make([]attribute.KeyValue, 0, len(m))
+	for key, value := range m {
+		attrs = append(attrs, log.ToAttribute(key, value))
+	}
+	AddToCurrentSpan(ctx, attrs...)
+}
+
+// TimedAttr runs fn and sets its wall-clock duration in milliseconds as the key attribute of the current span,
+// e.g. db.query_ms, so sub-operation timings can be aggregated.
+func TimedAttr(ctx context.Context, key string, fn func()) {
+	start := time.Now()
+	fn()
+	AddToCurrentSpan(ctx, attribute.Int64(key, time.Since(start).Milliseconds()))
+}
+
+// TimedAttrVal is TimedAttr for an fn returning a value.
+func TimedAttrVal[T interface{}](ctx context.Context, key string, fn func() T) T {
+	start := time.Now()
+	result := fn()
+	AddToCurrentSpan(ctx, attribute.Int64(key, time.Since(start).Milliseconds()))
+	return result
+}
+
+// SetPayloadSizes records the request and response payload sizes of the operation on the current span.
+func SetPayloadSizes(ctx context.Context, requestBytes, responseBytes int64) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		payloadRequestBytesKey.Int64(requestBytes),
+		payloadResponseBytesKey.Int64(responseBytes),
+	)
+}
+
+// SetStatus sets the status of the current span, sanitizing the description as configured through
+// SetStatusDescriptionLimit and RegisterStatusRedaction.
 func SetStatus(ctx context.Context, code codes.Code, description string) {
 	span := trace.SpanFromContext(ctx)
-	span.SetStatus(code, description)
+	span.SetStatus(code, sanitizeStatusDescription(description))
 }
 
 func RecordError(ctx context.Context, err error) {
@@ -26,22 +150,150 @@ func RecordError(ctx context.Context, err error) {
 	span.RecordError(err)
 }
 
-func InstrumentSpan[T interface{}](ctx context.Context, spanName string, consumer func(ctx context.Context) T) T {
-	if tracer == nil {
-		tracer = otel.GetTracerProvider().Tracer("fr-otel-tracer")
+// RecordErrorAndFail records err on the current span and sets its error status, doing nothing for a nil err.
+func RecordErrorAndFail(ctx context.Context, err error) {
+	if err == nil {
+		return
 	}
-	spanCtx, span := tracer.Start(ctx, spanName)
-	defer span.End()
+	RecordError(ctx, err)
+	SetStatus(ctx, codes.Error, err.Error())
+}
+
+// InstrumentSpan runs consumer in a span. A panic of the consumer is recorded on the span, which ends with
+// the error status, and then propagated. The lines the consumer logs through log.LoggerFromContext or the log ...Ctx
+// functions with its ctx carry the ids of the span, those of the package functions don't.
+func InstrumentSpan[T interface{}](ctx context.Context, spanName string, consumer func(ctx context.Context) T) T {
+	return instrumentSpan(ctx, spanName, consumer)
+}
+
+// InstrumentSpanWithAttrs is InstrumentSpan starting the span with attrs, so samplers deciding at span start see them.
+func InstrumentSpanWithAttrs[T interface{}](ctx context.Context, spanName string, attrs []attribute.KeyValue, consumer func(ctx context.Context) T) T {
+	return instrumentSpan(ctx, spanName, consumer, trace.WithAttributes(attrs...))
+}
+
+// InstrumentSpanWithKind is InstrumentSpan starting a span of kind, so tracing backends draw the service map right.
+// In a Lambda use trace.SpanKindConsumer for the handling of SQS, SNS, Kinesis or DynamoDB streams events,
+// trace.SpanKindServer for API Gateway and ALB requests, trace.SpanKindClient for outbound HTTP or AWS SDK calls
+// and trace.SpanKindProducer for publishing messages.
+func InstrumentSpanWithKind[T interface{}](ctx context.Context, spanName string, kind trace.SpanKind, consumer func(ctx context.Context) T) T {
+	return instrumentSpan(ctx, spanName, consumer, trace.WithSpanKind(kind))
+}
+
+// InstrumentSpanWithLinks is InstrumentSpan starting a span linked to links, e.g. the traces of the messages
+// of a batch processed together, see LinkFromCarrier.
+func InstrumentSpanWithLinks[T interface{}](ctx context.Context, spanName string, links []trace.Link, consumer func(ctx context.Context) T) T {
+	return instrumentSpan(ctx, spanName, consumer, trace.WithLinks(links...))
+}
+
+func instrumentSpan[T interface{}](ctx context.Context, spanName string, consumer func(ctx context.Context) T, startOpts ...trace.SpanStartOption) T {
+	// skip instrumentSpan and the instrument function calling it
+	spanCtx, span := startSpan(ctx, spanName, 2, startOpts...)
+	defer endSpan(span)
 
 	return consumer(spanCtx)
 }
 
-func InstrumentSpanWithErr[T interface{}](ctx context.Context, spanName string, consumer func(ctx context.Context) (T, error)) (T, error) {
-	if tracer == nil {
-		tracer = otel.GetTracerProvider().Tracer("fr-otel-tracer")
+// InstrumentOption adjusts how InstrumentSpanWithErr reflects the outcome of the consumer on the span.
+type InstrumentOption func(*instrumentOptions)
+
+type instrumentOptions struct {
+	ignoreError         func(err error) bool
+	recordContextErrors bool
+}
+
+// IgnoreErrors leaves the span successful, without recording the error, when the consumer returns an error
+// matching, e.g. a not found error which is an expected outcome.
+func IgnoreErrors(matching func(err error) bool) InstrumentOption {
+	return func(o *instrumentOptions) {
+		o.ignoreError = matching
 	}
-	spanCtx, span := tracer.Start(ctx, spanName)
-	defer span.End()
+}
 
-	return consumer(spanCtx)
+// RecordContextErrors marks the span with the timed_out or canceled attribute and the error status when the context
+// is past its deadline or canceled once the consumer returns, even if the consumer swallowed the context error.
+func RecordContextErrors() InstrumentOption {
+	return func(o *instrumentOptions) {
+		o.recordContextErrors = true
+	}
+}
+
+// InstrumentSpanWithErr runs consumer in a span which records the error the consumer returns and has the
+// error status then, the ok status otherwise. A panic of the consumer is handled as by InstrumentSpan.
+func InstrumentSpanWithErr[T interface{}](ctx context.Context, spanName string, consumer func(ctx context.Context) (T, error), opts ...InstrumentOption) (T, error) {
+	return instrumentSpanWithErr(ctx, spanName, consumer, nil, opts)
+}
+
+// InstrumentSpanWithErrAndAttrs is InstrumentSpanWithErr starting the span with attrs, so samplers deciding
+// at span start see them.
+func InstrumentSpanWithErrAndAttrs[T interface{}](ctx context.Context, spanName string, attrs []attribute.KeyValue, consumer func(ctx context.Context) (T, error), opts ...InstrumentOption) (T, error) {
+	return instrumentSpanWithErr(ctx, spanName, consumer, []trace.SpanStartOption{trace.WithAttributes(attrs...)}, opts)
+}
+
+// InstrumentSpanWithErrAndKind is InstrumentSpanWithErr starting a span of kind, see InstrumentSpanWithKind.
+func InstrumentSpanWithErrAndKind[T interface{}](ctx context.Context, spanName string, kind trace.SpanKind, consumer func(ctx context.Context) (T, error), opts ...InstrumentOption) (T, error) {
+	return instrumentSpanWithErr(ctx, spanName, consumer, []trace.SpanStartOption{trace.WithSpanKind(kind)}, opts)
+}
+
+func instrumentSpanWithErr[T interface{}](ctx context.Context, spanName string, consumer func(ctx context.Context) (T, error), startOpts []trace.SpanStartOption, opts []InstrumentOption) (T, error) {
+	var options instrumentOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// skip instrumentSpanWithErr and the instrument function calling it
+	spanCtx, span := startSpan(ctx, spanName, 2, startOpts...)
+	defer endSpan(span)
+
+	result, err := consumer(spanCtx)
+	contextDone := options.recordContextErrors && markContextError(spanCtx, span)
+	switch {
+	case err != nil && (options.ignoreError == nil || !options.ignoreError(err)):
+		RecordError(spanCtx, err)
+		SetStatus(spanCtx, codes.Error, err.Error())
+	case contextDone:
+		SetStatus(spanCtx, codes.Error, context.Cause(spanCtx).Error())
+	default:
+		SetStatus(spanCtx, codes.Ok, "")
+	}
+	return result, err
+}
+
+// markContextError adds the timed_out or canceled attribute to span when ctx is done, reporting whether it is.
+func markContextError(ctx context.Context, span trace.Span) bool {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		span.SetAttributes(timedOutKey.Bool(true))
+	case context.Canceled:
+		span.SetAttributes(canceledKey.Bool(true))
+	default:
+		return false
+	}
+	return true
+}
+
+// endSpan ends span, recording a panic in flight before resuming it. It has to be deferred to recover the panic.
+func endSpan(span trace.Span) {
+	if r := recover(); r != nil {
+		err, ok := r.(error)
+		if !ok {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		span.RecordError(err, trace.WithStackTrace(true))
+		span.SetStatus(codes.Error, sanitizeStatusDescription(err.Error()))
+		span.End()
+		panic(r)
+	}
+	span.End()
+}
+
+// CompressedSpans emits a single summary span in place of count repeated child spans which took total time together.
+// The summary span ends now and starts total before, so it covers the time spent by the children.
+func CompressedSpans(ctx context.Context, name string, count int, total time.Duration) {
+	end := time.Now()
+	_, span := getTracer().Start(ctx, name,
+		trace.WithTimestamp(end.Add(-total)),
+		trace.WithAttributes(
+			childCountKey.Int(count),
+			totalDurationKey.Int64(total.Milliseconds()),
+		))
+	span.End(trace.WithTimestamp(end))
 }
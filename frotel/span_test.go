@@ -0,0 +1,486 @@
+package frotel_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	frotel.ResetTracer()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		frotel.ResetTracer()
+	})
+	return recorder
+}
+
+func attributesOf(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestCompressedSpans(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.CompressedSpans(context.Background(), "cache-lookup", 1500, 3*time.Second)
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "cache-lookup", spans[0].Name())
+	attrs := attributesOf(spans[0])
+	assert.Equal(t, int64(1500), attrs["child.count"].AsInt64())
+	assert.Equal(t, int64(3000), attrs["total.duration_ms"].AsInt64())
+	assert.Equal(t, 3*time.Second, spans[0].EndTime().Sub(spans[0].StartTime()))
+}
+
+func TestSetPayloadSizes(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "upload", func(ctx context.Context) interface{} {
+		frotel.SetPayloadSizes(ctx, 512, 2048)
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	attrs := attributesOf(spans[0])
+	assert.Equal(t, int64(512), attrs["payload.request_bytes"].AsInt64())
+	assert.Equal(t, int64(2048), attrs["payload.response_bytes"].AsInt64())
+}
+
+func TestAddSpanEvent(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "fetch-fares", func(ctx context.Context) interface{} {
+		frotel.AddSpanEvent(ctx, "cache.miss", attribute.String("cache.key", "fares:DUB-STN"))
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Len(t, spans[0].Events(), 1)
+	event := spans[0].Events()[0]
+	assert.Equal(t, "cache.miss", event.Name)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("cache.key", "fares:DUB-STN")}, event.Attributes)
+}
+
+func TestRecordErrorAndFail(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "charge", func(ctx context.Context) interface{} {
+		frotel.RecordErrorAndFail(ctx, errors.New("card declined"))
+		return nil
+	})
+	frotel.InstrumentSpan(context.Background(), "refund", func(ctx context.Context) interface{} {
+		frotel.RecordErrorAndFail(ctx, nil)
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "card declined", spans[0].Status().Description)
+	assert.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, semconv.ExceptionEventName, spans[0].Events()[0].Name)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Empty(t, spans[1].Events())
+}
+
+func TestInstrumentSpanWithErrRecordsContextErrors(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	swallowing := func(ctx context.Context) (string, error) {
+		return "cached", nil
+	}
+	// WHEN
+	_, _ = frotel.InstrumentSpanWithErr(expired, "expired", swallowing, frotel.RecordContextErrors())
+	_, _ = frotel.InstrumentSpanWithErr(canceled, "canceled", swallowing, frotel.RecordContextErrors())
+	_, _ = frotel.InstrumentSpanWithErr(expired, "not recorded", swallowing)
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, attribute.BoolValue(true), attributesOf(spans[0])["timed_out"])
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), spans[0].Status().Description)
+	assert.Equal(t, attribute.BoolValue(true), attributesOf(spans[1])["canceled"])
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.NotContains(t, attributesOf(spans[2]), attribute.Key("timed_out"))
+	assert.Equal(t, codes.Ok, spans[2].Status().Code)
+}
+
+func TestTimedAttr(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	var rows int
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "booking", func(ctx context.Context) interface{} {
+		frotel.TimedAttr(ctx, "db.query_ms", func() {
+			time.Sleep(20 * time.Millisecond)
+		})
+		rows = frotel.TimedAttrVal(ctx, "cache.lookup_ms", func() int {
+			time.Sleep(10 * time.Millisecond)
+			return 3
+		})
+		return nil
+	})
+	// THEN
+	assert.Equal(t, 3, rows)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	attrs := attributesOf(spans[0])
+	assert.GreaterOrEqual(t, attrs["db.query_ms"].AsInt64(), int64(20))
+	assert.Less(t, attrs["db.query_ms"].AsInt64(), int64(1000))
+	assert.GreaterOrEqual(t, attrs["cache.lookup_ms"].AsInt64(), int64(10))
+	assert.Less(t, attrs["cache.lookup_ms"].AsInt64(), int64(1000))
+}
+
+func TestInstrumentSpanCorrelatesContextLogs(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	parentCtx, parent := frotel.StartSpan(context.Background(), "request")
+	// WHEN
+	entries := captureOutput(t, func() {
+		frotel.InstrumentSpan(parentCtx, "booking", func(ctx context.Context) interface{} {
+			log.InfoCtx(ctx, "Inside the span")
+			log.Info("Package logger inside the span")
+			return nil
+		})
+	})
+	parent.End()
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Len(t, entries, 2)
+	child := spans[0].SpanContext()
+	assert.NotEqual(t, parent.SpanContext().SpanID(), child.SpanID())
+	assert.Equal(t, child.SpanID().String(), entries[0][log.SpanId])
+	assert.Equal(t, child.TraceID().String(), entries[0][log.TraceId])
+	assert.NotContains(t, entries[1], log.SpanId)
+}
+
+func TestInstrumentSpanCorrelatesContextLogsOfConcurrentSpans(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	entries := captureOutput(t, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				frotel.InstrumentSpan(context.Background(), "booking", func(ctx context.Context) interface{} {
+					log.LoggerFromContext(ctx).Infow("Inside the span", "Body.booking.index", i)
+					return nil
+				})
+			}(i)
+		}
+		wg.Wait()
+	})
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 10)
+	require.Len(t, entries, 10)
+	spanIds := map[string]bool{}
+	for _, span := range spans {
+		spanIds[span.SpanContext().SpanID().String()] = true
+	}
+	logged := map[string]bool{}
+	for _, entry := range entries {
+		spanId, _ := entry[log.SpanId].(string)
+		assert.True(t, spanIds[spanId], entry)
+		logged[spanId] = true
+	}
+	assert.Len(t, logged, 10)
+}
+
+func TestSetSpanName(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "handler", func(ctx context.Context) interface{} {
+		frotel.SetSpanName(ctx, "POST /bookings")
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "POST /bookings", spans[0].Name())
+}
+
+type fareClass struct {
+	Code string
+}
+
+func TestAddMapToCurrentSpan(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "booking", func(ctx context.Context) interface{} {
+		frotel.AddMapToCurrentSpan(ctx, map[string]interface{}{
+			"booking.id":         "ABC123",
+			"booking.paid":       true,
+			"booking.passengers": 3,
+			"booking.miles":      int64(1200),
+			"booking.amount":     99.5,
+			"booking.seats":      []string{"1A", "1B"},
+			"booking.checked":    []bool{true, false},
+			"booking.bags":       []int{1, 2},
+			"booking.legs":       []int64{4, 5},
+			"booking.fares":      []float64{19.99, 29.99},
+			"booking.codes":      []interface{}{"FR", "RK"},
+			"booking.prices":     []interface{}{10.0, 20.5},
+			"booking.mixed":      []interface{}{"FR", 1.0},
+			"booking.class":      fareClass{Code: "Y"},
+		})
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	attrs := attributesOf(spans[0])
+	assert.Equal(t, attribute.StringValue("ABC123"), attrs["booking.id"])
+	assert.Equal(t, attribute.BoolValue(true), attrs["booking.paid"])
+	assert.Equal(t, attribute.Int64Value(3), attrs["booking.passengers"])
+	assert.Equal(t, attribute.Int64Value(1200), attrs["booking.miles"])
+	assert.Equal(t, attribute.Float64Value(99.5), attrs["booking.amount"])
+	assert.Equal(t, attribute.StringSliceValue([]string{"1A", "1B"}), attrs["booking.seats"])
+	assert.Equal(t, attribute.BoolSliceValue([]bool{true, false}), attrs["booking.checked"])
+	assert.Equal(t, attribute.IntSliceValue([]int{1, 2}), attrs["booking.bags"])
+	assert.Equal(t, attribute.Int64SliceValue([]int64{4, 5}), attrs["booking.legs"])
+	assert.Equal(t, attribute.Float64SliceValue([]float64{19.99, 29.99}), attrs["booking.fares"])
+	assert.Equal(t, attribute.StringSliceValue([]string{"FR", "RK"}), attrs["booking.codes"])
+	assert.Equal(t, attribute.Float64SliceValue([]float64{10, 20.5}), attrs["booking.prices"])
+	assert.Equal(t, attribute.StringValue("[FR 1]"), attrs["booking.mixed"])
+	assert.Equal(t, attribute.StringValue("{Code:Y}"), attrs["booking.class"])
+}
+
+func TestInstrumentSpanWithErrRecordsError(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	failure := errors.New("seat already taken")
+	// WHEN
+	_, err := frotel.InstrumentSpanWithErr(context.Background(), "reserve-seat", func(ctx context.Context) (interface{}, error) {
+		return nil, failure
+	})
+	// THEN
+	assert.Equal(t, failure, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "seat already taken", spans[0].Status().Description)
+	assert.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, semconv.ExceptionEventName, spans[0].Events()[0].Name)
+}
+
+func TestInstrumentSpanWithErrSetsOkStatus(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	result, err := frotel.InstrumentSpanWithErr(context.Background(), "reserve-seat", func(ctx context.Context) (string, error) {
+		return "12C", nil
+	})
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "12C", result)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Ok, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+}
+
+func TestInstrumentSpanWithErrIgnoresErrors(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	notFound := errors.New("booking not found")
+	// WHEN
+	_, err := frotel.InstrumentSpanWithErr(context.Background(), "find-booking", func(ctx context.Context) (interface{}, error) {
+		return nil, notFound
+	}, frotel.IgnoreErrors(func(err error) bool { return errors.Is(err, notFound) }))
+	// THEN
+	assert.Equal(t, notFound, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Ok, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+}
+
+func TestInstrumentSpanRecordsPanic(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	assert.PanicsWithValue(t, "seat map missing", func() {
+		frotel.InstrumentSpan(context.Background(), "render-seat-map", func(ctx context.Context) interface{} {
+			panic("seat map missing")
+		})
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "panic: seat map missing", spans[0].Status().Description)
+	assert.Len(t, spans[0].Events(), 1)
+	event := spans[0].Events()[0]
+	assert.Equal(t, semconv.ExceptionEventName, event.Name)
+	stacktrace := attribute.NewSet(event.Attributes...)
+	value, found := stacktrace.Value(semconv.ExceptionStacktraceKey)
+	assert.True(t, found)
+	assert.Contains(t, value.AsString(), "TestInstrumentSpanRecordsPanic")
+}
+
+func TestInstrumentSpanWithErrRecordsPanicError(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	failure := errors.New("connection reset")
+	// WHEN
+	assert.PanicsWithError(t, "connection reset", func() {
+		_, _ = frotel.InstrumentSpanWithErr(context.Background(), "fetch-fares", func(ctx context.Context) (interface{}, error) {
+			panic(failure)
+		})
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "connection reset", spans[0].Status().Description)
+}
+
+func TestInitTracer(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InitTracer("booking-service")
+	_, span := frotel.StartSpan(context.Background(), "book")
+	span.End()
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "booking-service", spans[0].InstrumentationScope().Name)
+}
+
+func TestStartSpansConcurrently(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			frotel.InstrumentSpan(context.Background(), "concurrent", func(ctx context.Context) interface{} { return nil })
+		}()
+	}
+	wg.Wait()
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 20)
+	for _, span := range spans {
+		assert.Equal(t, "fr-otel-tracer", span.InstrumentationScope().Name)
+	}
+}
+
+func TestInstrumentSpanWithAttrs(t *testing.T) {
+	// GIVEN
+	var sampledAttrs []attribute.KeyValue
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithSampler(samplerFunc(func(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+			sampledAttrs = p.Attributes
+			return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample}
+		})))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	frotel.ResetTracer()
+	defer func() {
+		otel.SetTracerProvider(previous)
+		frotel.ResetTracer()
+	}()
+	attrs := []attribute.KeyValue{attribute.String("booking.channel", "web"), attribute.Int("booking.passengers", 2)}
+	// WHEN
+	frotel.InstrumentSpanWithAttrs(context.Background(), "book", attrs, func(ctx context.Context) interface{} { return nil })
+	_, _ = frotel.InstrumentSpanWithErrAndAttrs(context.Background(), "pay", attrs, func(ctx context.Context) (interface{}, error) { return nil, nil })
+	// THEN
+	assert.Equal(t, attrs, sampledAttrs)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.Equal(t, "web", attributesOf(span)["booking.channel"].AsString())
+		assert.Equal(t, int64(2), attributesOf(span)["booking.passengers"].AsInt64())
+	}
+}
+
+func TestInstrumentSpanWithKind(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	frotel.InstrumentSpanWithKind(context.Background(), "handle-sqs", trace.SpanKindConsumer, func(ctx context.Context) interface{} {
+		_, _ = frotel.InstrumentSpanWithErrAndKind(ctx, "call-pricing", trace.SpanKindClient, func(ctx context.Context) (interface{}, error) {
+			return nil, nil
+		})
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "call-pricing", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, "handle-sqs", spans[1].Name())
+	assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind())
+}
+
+type samplerFunc func(p sdktrace.SamplingParameters) sdktrace.SamplingResult
+
+func (f samplerFunc) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return f(p)
+}
+
+func (f samplerFunc) Description() string {
+	return "samplerFunc"
+}
+
+func TestSetTracerNameWithScopeVersionAndSchemaURL(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	frotel.SetTracerName("booking-service",
+		trace.WithInstrumentationVersion("1.2.3"),
+		trace.WithSchemaURL(semconv.SchemaURL))
+	// WHEN
+	_, span := frotel.StartSpan(context.Background(), "scoped", trace.WithSpanKind(trace.SpanKindServer))
+	span.End()
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "booking-service", spans[0].InstrumentationScope().Name)
+	assert.Equal(t, "1.2.3", spans[0].InstrumentationScope().Version)
+	assert.Equal(t, semconv.SchemaURL, spans[0].InstrumentationScope().SchemaURL)
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+}
@@ -0,0 +1,41 @@
+package frotel
+
+import (
+	"regexp"
+	"sync"
+)
+
+const redactedStatus = "***"
+
+var (
+	statusMu               sync.RWMutex
+	statusDescriptionLimit int
+	statusRedactions       []*regexp.Regexp
+)
+
+// SetStatusDescriptionLimit truncates span status descriptions set through frotel to max characters, 0 means no limit.
+func SetStatusDescriptionLimit(max int) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statusDescriptionLimit = max
+}
+
+// RegisterStatusRedaction masks every match of patterns in span status descriptions set through frotel,
+// e.g. e-mail addresses taken over from error messages.
+func RegisterStatusRedaction(patterns ...*regexp.Regexp) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statusRedactions = append(statusRedactions, patterns...)
+}
+
+func sanitizeStatusDescription(description string) string {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	for _, pattern := range statusRedactions {
+		description = pattern.ReplaceAllString(description, redactedStatus)
+	}
+	if runes := []rune(description); statusDescriptionLimit > 0 && len(runes) > statusDescriptionLimit {
+		description = string(runes[:statusDescriptionLimit])
+	}
+	return description
+}
@@ -0,0 +1,27 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"regexp"
+	"testing"
+)
+
+func TestSetStatusSanitizesDescription(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	frotel.RegisterStatusRedaction(regexp.MustCompile(`[\w.]+@[\w.]+`))
+	frotel.SetStatusDescriptionLimit(32)
+	defer frotel.SetStatusDescriptionLimit(0)
+	// WHEN
+	frotel.InstrumentSpan(context.Background(), "notify", func(ctx context.Context) interface{} {
+		frotel.SetStatus(ctx, codes.Error, "cannot notify john.doe@example.com: mailbox unavailable, retry later")
+		return nil
+	})
+	// THEN
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "cannot notify ***: mailbox unava", spans[0].Status().Description)
+}
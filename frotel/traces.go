@@ -7,6 +7,8 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/multierr"
+	"time"
 )
 
 func NewTraceProvider(ctx context.Context) (*trace.TracerProvider, error) {
@@ -33,3 +35,40 @@ func NewTraceProvider(ctx context.Context) (*trace.TracerProvider, error) {
 	otel.SetTracerProvider(tp)
 	return tp, nil
 }
+
+// ForceFlush exports the spans completed so far without waiting for the batch timer, e.g. before a risky external call.
+// It does nothing when the global tracer provider has no processors to flush.
+func ForceFlush(ctx context.Context) error {
+	if flusher, ok := otel.GetTracerProvider().(interface{ ForceFlush(context.Context) error }); ok {
+		return flusher.ForceFlush(ctx)
+	}
+	return nil
+}
+
+const defaultShutdownTimeout = 5 * time.Second
+
+// Shutdown flushes and stops the global tracer and meter providers, so the spans and metrics buffered by batching
+// processors aren't lost when a Lambda execution environment freezes. Without a deadline in ctx it gives up after
+// 5 seconds. Call it last, together with log.Flush, e.g.
+//
+//	defer func() {
+//		_ = frotel.Shutdown(ctx)
+//		_ = log.Flush()
+//	}()
+//
+// The providers can't be used once shut down, so a handler served many times should call ForceFlush instead.
+func Shutdown(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultShutdownTimeout)
+		defer cancel()
+	}
+	var errs error
+	if provider, ok := otel.GetTracerProvider().(interface{ Shutdown(context.Context) error }); ok {
+		errs = multierr.Append(errs, provider.Shutdown(ctx))
+	}
+	if provider, ok := otel.GetMeterProvider().(interface{ Shutdown(context.Context) error }); ok {
+		errs = multierr.Append(errs, provider.Shutdown(ctx))
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+package frotel_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestForceFlush(t *testing.T) {
+	// GIVEN
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Hour)))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	frotel.ResetTracer()
+	defer func() {
+		otel.SetTracerProvider(previous)
+		frotel.ResetTracer()
+	}()
+	frotel.InstrumentSpan(context.Background(), "checkpoint", func(ctx context.Context) interface{} {
+		return nil
+	})
+	assert.Empty(t, exporter.GetSpans())
+	// WHEN
+	err := frotel.ForceFlush(context.Background())
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, exporter.GetSpans(), 1)
+	assert.Equal(t, "checkpoint", exporter.GetSpans()[0].Name)
+}
+
+func TestForceFlushWithoutBatchProcessor(t *testing.T) {
+	// GIVEN
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	defer otel.SetTracerProvider(previous)
+	// WHEN
+	err := frotel.ForceFlush(context.Background())
+	// THEN
+	assert.NoError(t, err)
+}
+
+// recordingExporter keeps the exported spans past its shutdown, unlike tracetest.InMemoryExporter.
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *recordingExporter) exported() []sdktrace.ReadOnlySpan {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.spans
+}
+
+func TestShutdown(t *testing.T) {
+	// GIVEN
+	exporter := &recordingExporter{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Hour)))
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader))
+	previousTracer, previousMeter := otel.GetTracerProvider(), otel.GetMeterProvider()
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+	frotel.ResetTracer()
+	defer func() {
+		otel.SetTracerProvider(previousTracer)
+		otel.SetMeterProvider(previousMeter)
+		frotel.ResetTracer()
+	}()
+	frotel.InstrumentSpan(context.Background(), "handler", func(ctx context.Context) interface{} {
+		return nil
+	})
+	assert.Empty(t, exporter.exported())
+	// WHEN
+	err := frotel.Shutdown(context.Background())
+	// THEN
+	assert.NoError(t, err)
+	spans := exporter.exported()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "handler", spans[0].Name())
+	assert.Error(t, reader.Collect(context.Background(), &metricdata.ResourceMetrics{}))
+}
@@ -0,0 +1,51 @@
+package frotel
+
+import (
+	"context"
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	txBeginEvent    = "transaction.begin"
+	txCommitEvent   = "transaction.commit"
+	txRollbackEvent = "transaction.rollback"
+
+	txNameKey  = "Body.transaction.name"
+	txErrorKey = attribute.Key("transaction.error")
+)
+
+// InstrumentTx runs fn, which is expected to wrap a database transaction, inside a span named name.
+// Begin, commit and rollback are recorded as span events and log entries; an error returned by fn counts as a rollback
+// and marks the span as failed, as does a panic of fn, which is resumed afterwards.
+func InstrumentTx(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	txCtx, span := getTracer().Start(ctx, name)
+	defer endSpan(span)
+	logger := log.LoggerFromContext(txCtx)
+	defer func() {
+		if r := recover(); r != nil {
+			span.AddEvent(txRollbackEvent, trace.WithAttributes(txErrorKey.String(fmt.Sprintf("panic: %v", r))))
+			logger.Warnw("Transaction rollback", txNameKey, name, log.ErrorMessage, fmt.Sprintf("panic: %v", r))
+			panic(r)
+		}
+	}()
+
+	span.AddEvent(txBeginEvent)
+	logger.Debugw("Transaction begin", txNameKey, name)
+
+	if err := fn(txCtx); err != nil {
+		span.AddEvent(txRollbackEvent, trace.WithAttributes(txErrorKey.String(err.Error())))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, sanitizeStatusDescription(err.Error()))
+		logger.Warnw("Transaction rollback", txNameKey, name, log.ErrorMessage, err.Error())
+		return err
+	}
+
+	span.AddEvent(txCommitEvent)
+	span.SetStatus(codes.Ok, "")
+	logger.Debugw("Transaction commit", txNameKey, name)
+	return nil
+}
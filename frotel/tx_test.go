@@ -0,0 +1,88 @@
+package frotel_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"testing"
+)
+
+func eventNames(span sdktrace.ReadOnlySpan) []string {
+	var names []string
+	for _, event := range span.Events() {
+		names = append(names, event.Name)
+	}
+	return names
+}
+
+func TestInstrumentTxCommit(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	err := frotel.InstrumentTx(context.Background(), "save-order", func(ctx context.Context) error {
+		return nil
+	})
+	// THEN
+	assert.NoError(t, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, []string{"transaction.begin", "transaction.commit"}, eventNames(spans[0]))
+	assert.Equal(t, codes.Ok, spans[0].Status().Code)
+}
+
+func TestInstrumentTxRollback(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	txErr := errors.New("constraint violation")
+	// WHEN
+	err := frotel.InstrumentTx(context.Background(), "save-order", func(ctx context.Context) error {
+		return txErr
+	})
+	// THEN
+	assert.Equal(t, txErr, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, []string{"transaction.begin", "transaction.rollback", "exception"}, eventNames(spans[0]))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "constraint violation", spans[0].Status().Description)
+}
+
+func TestInstrumentTxRollbackLogsTraceAndError(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	entries := captureOutput(t, func() {
+		_ = frotel.InstrumentTx(context.Background(), "save-order", func(ctx context.Context) error {
+			return errors.New("constraint violation")
+		})
+	})
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Transaction rollback", entries[1][log.Message])
+	assert.Equal(t, "constraint violation", entries[1][log.ErrorMessage])
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), entries[1][log.TraceId])
+}
+
+func TestInstrumentTxPanic(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	// WHEN
+	assert.PanicsWithValue(t, "connection lost", func() {
+		_ = frotel.InstrumentTx(context.Background(), "save-order", func(ctx context.Context) error {
+			panic("connection lost")
+		})
+	})
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, []string{"transaction.begin", "transaction.rollback", "exception"}, eventNames(spans[0]))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "panic: connection lost", spans[0].Status().Description)
+}
@@ -0,0 +1,53 @@
+package frotel
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const defaultHandlerSpanName = "handler"
+
+// WrapHandler wraps a typed Lambda handler with the logging and tracing boilerplate: it sets up the trace ids and
+// the Lambda context fields of the invocation, see log.SetupLambdaContext, runs handler in a span named after
+// the function, flagged with faas.coldstart as the ColdStart log field, logs the invocation at debug level,
+// records errors and panics and flushes the logs.
+// The span is a server span unless opts set another kind, e.g. trace.WithSpanKind(trace.SpanKindConsumer) for
+// the SQS, SNS, Kinesis or DynamoDB streams triggers, see InstrumentSpanWithKind. With RecordSourceLocation
+// the span reports handler as its source location.
+func WrapHandler[TEvent, TResp interface{}](handler func(ctx context.Context, event TEvent) (TResp, error), opts ...trace.SpanStartOption) func(ctx context.Context, event TEvent) (TResp, error) {
+	return func(ctx context.Context, event TEvent) (TResp, error) {
+		defer log.Flush()
+		ctx = log.SetupLambdaContext(log.SetupTraceIds(ctx))
+
+		spanName := lambdacontext.FunctionName
+		if spanName == "" {
+			spanName = defaultHandlerSpanName
+		}
+		startOpts := []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindServer)}
+		if lc, ok := lambdacontext.FromContext(ctx); ok {
+			startOpts = append(startOpts, trace.WithAttributes(
+				semconv.FaaSColdstart(log.IsColdStart(ctx)),
+				semconv.FaaSInvocationID(lc.AwsRequestID),
+			))
+		}
+		if sourceLocationEnabled.Load() {
+			startOpts = append(startOpts, trace.WithAttributes(functionAttributes(handler)...))
+		}
+		startOpts = append(startOpts, opts...)
+
+		return instrumentSpanWithErr(ctx, spanName, func(ctx context.Context) (TResp, error) {
+			start := time.Now()
+			log.DebugWCtx(ctx, "Invocation started", "Body.invocation.function", spanName)
+			resp, err := handler(ctx, event)
+			log.DebugWCtx(ctx, "Invocation finished",
+				"Body.invocation.function", spanName,
+				"Body.invocation.durationMs", time.Since(start).Milliseconds(),
+				"Body.invocation.failed", err != nil)
+			return resp, err
+		}, startOpts, nil)
+	}
+}
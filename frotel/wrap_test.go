@@ -0,0 +1,133 @@
+package frotel_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Ryanair/gofrlib/frotel"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"testing"
+)
+
+type bookingEvent struct {
+	BookingId string
+}
+
+func TestWrapHandler(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	failure := errors.New("booking locked")
+	handler := frotel.WrapHandler(func(ctx context.Context, event bookingEvent) (string, error) {
+		if event.BookingId == "LOCKED" {
+			return "", failure
+		}
+		return "confirmed " + event.BookingId, nil
+	})
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "request-1"})
+
+	// WHEN
+	var resp string
+	var err error
+	entries := captureOutput(t, func() {
+		resp, err = handler(ctx, bookingEvent{BookingId: "ABC123"})
+		_, _ = handler(ctx, bookingEvent{BookingId: "LOCKED"})
+	})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "confirmed ABC123", resp)
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "handler", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, entries[0][log.ColdStart], attributesOf(spans[0])["faas.coldstart"].AsBool())
+	assert.Equal(t, "request-1", attributesOf(spans[0])["faas.invocation_id"].AsString())
+	assert.Equal(t, codes.Ok, spans[0].Status().Code)
+	assert.False(t, attributesOf(spans[1])["faas.coldstart"].AsBool())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "booking locked", spans[1].Status().Description)
+
+	require.Len(t, entries, 4)
+	assert.Equal(t, "Invocation started", entries[0][log.Message])
+	assert.Equal(t, "request-1", entries[0][log.AWSRequestID])
+	assert.Equal(t, false, entries[2][log.ColdStart])
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), entries[0][log.TraceId])
+	assert.Equal(t, "Invocation finished", entries[1][log.Message])
+	assert.Equal(t, "DEBUG", entries[1][log.Level])
+	assert.Equal(t, false, entries[1]["Body.invocation.failed"])
+	assert.Equal(t, true, entries[3]["Body.invocation.failed"])
+}
+
+func TestWrapHandlerRecordsPanic(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	handler := frotel.WrapHandler(func(ctx context.Context, event bookingEvent) (interface{}, error) {
+		panic("nil booking")
+	})
+	// WHEN
+	assert.Panics(t, func() { _, _ = handler(context.Background(), bookingEvent{}) })
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestWrapHandlerWithSpanKind(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	handler := frotel.WrapHandler(func(ctx context.Context, event bookingEvent) (interface{}, error) {
+		return nil, nil
+	}, trace.WithSpanKind(trace.SpanKindConsumer))
+	// WHEN
+	_, err := handler(context.Background(), bookingEvent{})
+	// THEN
+	assert.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, trace.SpanKindConsumer, spans[0].SpanKind())
+}
+
+func confirmBooking(_ context.Context, event bookingEvent) (string, error) {
+	return "confirmed " + event.BookingId, nil
+}
+
+func TestWrapHandlerRecordsHandlerSourceLocation(t *testing.T) {
+	// GIVEN
+	recorder := newSpanRecorder(t)
+	frotel.RecordSourceLocation(true, 0)
+	defer frotel.RecordSourceLocation(false, 0)
+	handler := frotel.WrapHandler(confirmBooking)
+	// WHEN
+	_, err := handler(context.Background(), bookingEvent{BookingId: "ABC123"})
+	// THEN
+	assert.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	attrs := attributesOf(spans[0])
+	assert.Equal(t, "github.com/Ryanair/gofrlib/frotel_test.confirmBooking", attrs["code.function"].AsString())
+	assert.True(t, strings.HasSuffix(attrs["code.filepath"].AsString(), "frotel/wrap_test.go"), attrs["code.filepath"].AsString())
+	assert.Equal(t, int64(95), attrs["code.lineno"].AsInt64())
+}
+
+func TestWrapHandlerRefreshesWatchedLevel(t *testing.T) {
+	// GIVEN
+	previous := log.GetLevel()
+	defer func() { _ = log.SetLevel(previous) }()
+	require.NoError(t, log.SetLevel("INFO"))
+	stop := log.WatchLevel(func(context.Context) (string, error) { return "WARN", nil }, 1)
+	defer stop()
+	handler := frotel.WrapHandler(func(ctx context.Context, event bookingEvent) (interface{}, error) {
+		return nil, nil
+	})
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "request-1"})
+	// WHEN
+	_, err := handler(ctx, bookingEvent{})
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "WARN", log.GetLevel())
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ToAttribute infers the attribute type from value, stringifying the types attributes can't hold.
+func ToAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case []bool:
+		return attribute.BoolSlice(key, v)
+	case []int:
+		return attribute.IntSlice(key, v)
+	case []int64:
+		return attribute.Int64Slice(key, v)
+	case []float64:
+		return attribute.Float64Slice(key, v)
+	case []interface{}:
+		return sliceAttribute(key, v)
+	case fmt.Stringer:
+		return attribute.Stringer(key, v)
+	default:
+		return attribute.String(key, fmt.Sprintf("%+v", v))
+	}
+}
+
+// sliceAttribute converts the slices decoded from JSON, typed []interface{}, whose items all have the same
+// supported type, stringifying any other.
+func sliceAttribute(key string, values []interface{}) attribute.KeyValue {
+	if len(values) == 0 {
+		return attribute.StringSlice(key, []string{})
+	}
+	switch values[0].(type) {
+	case string:
+		if items, ok := sliceOf[string](values); ok {
+			return attribute.StringSlice(key, items)
+		}
+	case bool:
+		if items, ok := sliceOf[bool](values); ok {
+			return attribute.BoolSlice(key, items)
+		}
+	case int:
+		if items, ok := sliceOf[int](values); ok {
+			return attribute.IntSlice(key, items)
+		}
+	case int64:
+		if items, ok := sliceOf[int64](values); ok {
+			return attribute.Int64Slice(key, items)
+		}
+	case float64:
+		if items, ok := sliceOf[float64](values); ok {
+			return attribute.Float64Slice(key, items)
+		}
+	}
+	return attribute.String(key, fmt.Sprintf("%+v", values))
+}
+
+func sliceOf[T any](values []interface{}) ([]T, bool) {
+	items := make([]T, 0, len(values))
+	for _, value := range values {
+		item, ok := value.(T)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, item)
+	}
+	return items, true
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+const baggagePrefix = "Body.baggage."
+
+// WithBaggageKeys makes LoggerFromContext and the ...Ctx functions log the members of the OpenTelemetry baggage
+// of the context named one of keys as Body.baggage.<key> fields. Other members are never logged.
+func (c Configuration) WithBaggageKeys(keys ...string) Configuration {
+	c.baggageKeys = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		c.baggageKeys[key] = true
+	}
+	return c
+}
+
+func baggageFields(ctx context.Context) []interface{} {
+	logMu.RLock()
+	allowed := logConfig.baggageKeys
+	logMu.RUnlock()
+	return allowedBaggageFields(ctx, allowed)
+}
+
+func allowedBaggageFields(ctx context.Context, allowed map[string]bool) []interface{} {
+	if len(allowed) == 0 {
+		return nil
+	}
+	var fields []interface{}
+	for _, member := range baggage.FromContext(ctx).Members() {
+		if allowed[member.Key()] {
+			fields = append(fields, baggagePrefix+member.Key(), member.Value())
+		}
+	}
+	return fields
+}
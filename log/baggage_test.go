@@ -0,0 +1,51 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"testing"
+)
+
+func contextWithBaggage(t *testing.T, members map[string]string) context.Context {
+	t.Helper()
+	var list []baggage.Member
+	for key, value := range members {
+		member, err := baggage.NewMember(key, value)
+		require.NoError(t, err)
+		list = append(list, member)
+	}
+	bag, err := baggage.New(list...)
+	require.NoError(t, err)
+	return baggage.ContextWithBaggage(context.Background(), bag)
+}
+
+func TestWithBaggageKeys(t *testing.T) {
+	// GIVEN
+	ctx := contextWithBaggage(t, map[string]string{"tenant": "FR", "feature.flags": "new-seatmap"})
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithBaggageKeys("tenant"), func() {
+		log.InfoWCtx(ctx, "Booking created")
+		log.LoggerFromContext(ctx).Info("Booking confirmed")
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, "FR", entry["Body.baggage.tenant"])
+		assert.NotContains(t, entry, "Body.baggage.feature.flags")
+	}
+}
+
+func TestBaggageNotLoggedByDefault(t *testing.T) {
+	// GIVEN
+	ctx := contextWithBaggage(t, map[string]string{"tenant": "FR"})
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.InfoWCtx(ctx, "Booking created")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0], "Body.baggage.tenant")
+}
@@ -0,0 +1,57 @@
+package log_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"testing"
+)
+
+// facadeInfo stands for a logging facade wrapping the log package.
+func facadeInfo(msg string) {
+	log.InfoW(msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	// WHEN
+	var line int
+	entries := captureOutput(t, testConfiguration("INFO").WithCallerSkip(2), func() {
+		_, _, line, _ = runtime.Caller(0)
+		facadeInfo("through the facade")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, fmt.Sprintf("log/caller_test.go:%d", line+1), entries[0][log.Logger])
+}
+
+func TestCallerSkipDefault(t *testing.T) {
+	// WHEN
+	var line int
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		_, _, line, _ = runtime.Caller(0)
+		log.Info("directly")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, fmt.Sprintf("log/caller_test.go:%d", line+1), entries[0][log.Logger])
+}
+
+func TestCallerOfLoggerFromContext(t *testing.T) {
+	// WHEN
+	var line int
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		ctx := context.Background()
+		_, _, line, _ = runtime.Caller(0)
+		log.LoggerFromContext(ctx).Info("through the context logger")
+		log.FromContext(ctx).Info("through FromContext")
+		log.InfoCtx(ctx, "through the ...Ctx function")
+	})
+	// THEN
+	require.Len(t, entries, 3)
+	for i, entry := range entries {
+		assert.Equal(t, fmt.Sprintf("log/caller_test.go:%d", line+1+i), entry[log.Logger])
+	}
+}
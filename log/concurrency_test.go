@@ -0,0 +1,38 @@
+package log_test
+
+import (
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentWith(t *testing.T) {
+	// GIVEN
+	ctx := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				log.With(fmt.Sprintf("Body.worker%d", i), i)
+				log.WithCustomAttr(fmt.Sprintf("attr%d", i), i)
+				log.SetupTraceIds(ctx)
+				log.InfoCtx(ctx, "worker %d", i)
+			}(i)
+		}
+		wg.Wait()
+		log.Info("done")
+	})
+	// THEN
+	assert.Len(t, entries, 21)
+	last := entries[20]
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, float64(i), last[fmt.Sprintf("Body.worker%d", i)])
+		assert.Equal(t, float64(i), last[fmt.Sprintf("Body.testprefix.attr%d", i)])
+	}
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", last[log.TraceId])
+}
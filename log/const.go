@@ -5,6 +5,23 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+// EncodingEnv names the environment variable choosing the encoding when WithEncoding isn't used.
+const EncodingEnv = "GOFRLIB_LOG_FORMAT"
+
+// Stdout and Stderr are the output paths of the standard streams, see WithOutputPaths.
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+// LogSchemaVersion is emitted as SchemaVersion on every entry. Bump it whenever the set of emitted fields changes.
+const LogSchemaVersion = "1"
+
 const (
 	TraceId       = "TraceId"
 	CorrelationId = "CorrelationId"
@@ -13,10 +30,15 @@ const (
 
 	Timestamp = "Timestamp"
 	Level     = "SeverityText"
+	Sequence  = "Seq"
 
 	Message    = "Body.message"
 	StackTrace = "Body.stacktrace"
 
+	ErrorMessage = "Body.error.message"
+	ErrorCode    = "Body.error.code"
+	ErrorStack   = "Body.error.stack"
+
 	Logger                 = "Resource.logger"
 	Application            = "Resource.application"
 	Project                = "Resource.project"
@@ -24,6 +46,17 @@ const (
 	ResourceServiceName    = "Resource.service.name"
 	ResourceServiceVersion = "Resource.service.version"
 	Version                = "Resource.version"
+	Module                 = "Resource.module"
+	SchemaVersion          = "Resource.schemaVersion"
+
+	DataPointSeries    = "Body.datapoint.series"
+	DataPointTimestamp = "Body.datapoint.timestamp"
+	DataPointValue     = "Body.datapoint.value"
+	DataPointTags      = "Body.datapoint.tags"
+
+	SagaId       = "Body.saga.id"
+	SagaStepName = "Body.saga.step"
+	SagaStatus   = "Body.saga.status"
 
 	EventSource = "Body.origin.event.eventSource"
 	EventBody   = "Body.origin.event.eventBody"
@@ -34,6 +67,17 @@ const (
 	MessagingSourceSystemKinesis         = "kinesis"
 )
 
+// ReservedKeys returns the keys of the fields the package itself writes, which user fields mustn't collide with.
+func ReservedKeys() []string {
+	return []string{
+		TraceId, CorrelationId, SpanId, TraceFlags,
+		Timestamp, Level, Sequence,
+		Message, StackTrace, ErrorMessage, ErrorCode, ErrorStack,
+		Logger, Application, Project, ProjectGroup, ResourceServiceName, ResourceServiceVersion, Version, Module,
+		SchemaVersion, AWSRequestID, ColdStart,
+	}
+}
+
 var MessagingSourceSystemDynamoDbStreamsMessageKey = attribute.Key(fmt.Sprintf("messaging.%s.message.key", MessagingSourceSystemDynamoDbStreams))
 
 var MessagingMessageShard = attribute.Key("messaging.message.shard")
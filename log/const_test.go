@@ -0,0 +1,35 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestReservedKeysMatchEmittedFields(t *testing.T) {
+	// GIVEN
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId,
+		SpanID:  spanId,
+	}))
+	ctx = lambdacontext.NewContext(ctx, &lambdacontext.LambdaContext{AwsRequestID: "request-1"})
+	config := testConfiguration("INFO").WithModuleField(true).WithSequence(true)
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		ctx = log.SetupLambdaContext(ctx)
+		log.LogError(ctx, stackError{bookingError{code: "BOOKING_FULL"}}, "Every reserved field")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	var keys []string
+	for key := range entries[0] {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, log.ReservedKeys(), keys)
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+type contextFieldsKey struct{}
+
+// ContextWith returns a copy of ctx carrying keysAndValues in addition to the fields already attached to ctx.
+// Unlike With it leaves the package logger untouched, so the fields live only as long as the request context.
+func ContextWith(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	fields := append(append([]interface{}{}, contextFields(ctx)...), keysAndValues...)
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+// ContextWithCustomAttr is the context scoped WithCustomAttr, attaching key as a Body.<customAttributesPrefix>.<key>
+// field to the loggers of the returned context only. A key WithCustomAttrE would reject leaves ctx unchanged,
+// with a warning. The WithMaxCustomAttributes limit applies to the package logger alone.
+func ContextWithCustomAttr(ctx context.Context, key string, value interface{}) context.Context {
+	logger()
+	logMu.RLock()
+	prefix := logConfig.customAttributesPrefix
+	logMu.RUnlock()
+	attr, err := customAttrField(prefix, key)
+	if err != nil {
+		loggerFromContext(ctx).Warnf("custom attribute %s dropped, %v", key, err)
+		return ctx
+	}
+	return ContextWith(ctx, attr, value)
+}
+
+// FromContext returns the logger enriched with the fields attached to ctx through ContextWith.
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	return LoggerFromContext(ctx)
+}
+
+// LoggerFromContext returns a request scoped logger carrying the trace fields of ctx, in place of those set by
+// SetupTraceIds, the allowed baggage members of ctx and the fields attached to ctx through ContextWith.
+// The package logger is left untouched.
+func LoggerFromContext(ctx context.Context) *zap.SugaredLogger {
+	// as for SugaredLogger, the callers of the returned logger have no package function frame to skip
+	return loggerFromContext(ctx).WithOptions(zap.AddCallerSkip(-1))
+}
+
+// loggerFromContext is LoggerFromContext for the package functions, skipping their frame in the caller.
+func loggerFromContext(ctx context.Context) *zap.SugaredLogger {
+	base := baseLogger()
+	fields := append(append(traceFields(ctx), baggageFields(ctx)...), contextFields(ctx)...)
+	if len(fields) == 0 {
+		return base
+	}
+	return base.With(fields...)
+}
+
+func contextFields(ctx context.Context) []interface{} {
+	if fields, ok := ctx.Value(contextFieldsKey{}).([]interface{}); ok {
+		return fields
+	}
+	return nil
+}
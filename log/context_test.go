@@ -0,0 +1,127 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	// GIVEN
+	ctx := log.ContextWith(context.Background(), "test-key-1", "test-value-1")
+	ctx = log.ContextWith(ctx, "test-key-2", 2)
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.FromContext(ctx).Info("Info msg with context fields")
+		log.Info("Info msg without context fields")
+	})
+	// THEN
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "test-value-1", entries[0]["test-key-1"])
+	assert.Equal(t, float64(2), entries[0]["test-key-2"])
+	assert.NotContains(t, entries[1], "test-key-1")
+}
+
+func TestSetupTraceIdsReplacesPreviousTraceFields(t *testing.T) {
+	// GIVEN
+	first := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	second := spanContext(t, "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331")
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.WithCustomAttr("tenant", "FR")
+		log.SetupTraceIds(first)
+		log.SetupTraceIds(second)
+		log.Info("second invocation")
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	assert.Equal(t, 1, strings.Count(lines[0], `"TraceId"`))
+	assert.Contains(t, lines[0], `"TraceId":"0af7651916cd43dd8448eb211c80319c"`)
+	assert.Contains(t, lines[0], `"Body.testprefix.tenant":"FR"`)
+}
+
+func TestLoggerFromContextLeavesPackageLoggerUntouched(t *testing.T) {
+	// GIVEN
+	ctx := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.LoggerFromContext(ctx).Info("request scoped")
+		log.Info("package logger")
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entries[0][log.TraceId])
+	assert.Equal(t, "00f067aa0ba902b7", entries[0][log.SpanId])
+	assert.NotContains(t, entries[1], log.TraceId)
+}
+
+func TestResetRequestFields(t *testing.T) {
+	// GIVEN
+	ctx := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.SetupTraceIds(ctx)
+		log.With("Body.booking.id", "ABC123")
+		log.ResetRequestFields()
+		log.Info("next invocation")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0], log.TraceId)
+	assert.NotContains(t, entries[0], "Body.booking.id")
+	assert.Equal(t, "test-application", entries[0][log.Application])
+}
+
+func spanContext(t *testing.T, traceIdHex, spanIdHex string) context.Context {
+	traceId, err := trace.TraceIDFromHex(traceIdHex)
+	require.NoError(t, err)
+	spanId, err := trace.SpanIDFromHex(spanIdHex)
+	require.NoError(t, err)
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId,
+		SpanID:  spanId,
+	}))
+}
+
+func TestContextWithCustomAttr(t *testing.T) {
+	// WHEN
+	var ctx context.Context
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		ctx = log.ContextWithCustomAttr(context.Background(), "tenant", "FR")
+		rejected := log.ContextWithCustomAttr(ctx, "", "dropped")
+		log.InfoWCtx(rejected, "Info msg with context custom attribute")
+		log.Info("Info msg without custom attribute")
+	})
+	// THEN
+	require.Len(t, entries, 3)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+	assert.Equal(t, "FR", entries[0]["Body.testprefix.tenant"])
+	assert.Equal(t, "FR", entries[1]["Body.testprefix.tenant"])
+	assert.NotContains(t, entries[2], "Body.testprefix.tenant")
+}
+
+func TestContextWithDoesNotLeakBetweenGoroutines(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		var wg sync.WaitGroup
+		for _, tenant := range []string{"FR", "RK", "AL"} {
+			wg.Add(1)
+			go func(tenant string) {
+				defer wg.Done()
+				ctx := log.ContextWithCustomAttr(context.Background(), "tenant", tenant)
+				log.InfoWCtx(ctx, "Invocation", "Body.invocation.tenant", tenant)
+			}(tenant)
+		}
+		wg.Wait()
+	})
+	// THEN
+	require.Len(t, entries, 3)
+	for _, entry := range entries {
+		assert.Equal(t, entry["Body.invocation.tenant"], entry["Body.testprefix.tenant"])
+	}
+}
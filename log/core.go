@@ -0,0 +1,160 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// entryHook adjusts an entry and its fields right before they reach the encoder.
+type entryHook func(entry zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field)
+
+// hookedCore runs hooks on every entry written through the wrapped core.
+type hookedCore struct {
+	zapcore.Core
+	hooks []entryHook
+}
+
+func (c *hookedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &hookedCore{Core: c.Core.With(fields), hooks: c.hooks}
+}
+
+func (c *hookedCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *hookedCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	for _, hook := range c.hooks {
+		entry, fields = hook(entry, fields)
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// levelCheckedCore drops the entries below its level on Write. The wrapping cores check the level of a tee as a whole,
+// so an entry only a sink takes would otherwise reach every core of the tee.
+type levelCheckedCore struct {
+	zapcore.Core
+}
+
+func (c *levelCheckedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCheckedCore{Core: c.Core.With(fields)}
+}
+
+func (c *levelCheckedCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *levelCheckedCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.Enabled(entry.Level) {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
+
+func buildHooks(config Configuration) []entryHook {
+	var hooks []entryHook
+	if config.moduleField {
+		hooks = append(hooks, moduleHook)
+	}
+	return hooks
+}
+
+var defaultSampling = zap.SamplingConfig{Initial: 100, Thereafter: 100}
+
+func samplingConfig(config Configuration) *zap.SamplingConfig {
+	if config.samplingDisabled {
+		return nil
+	}
+	if s := config.sampling; s != nil && s.Initial >= 0 && s.Thereafter >= 0 {
+		return s
+	}
+	return &defaultSampling
+}
+
+// levelSamplerCore samples every level with a sampler of its own, so the levels can be sampled differently.
+type levelSamplerCore struct {
+	zapcore.Core
+	samplers map[zapcore.Level]zapcore.Core
+}
+
+func newLevelSamplerCore(core zapcore.Core, sampling *zap.SamplingConfig, levelSampling map[zapcore.Level]*zap.SamplingConfig) *levelSamplerCore {
+	samplers := map[zapcore.Level]zapcore.Core{}
+	for l := zapcore.DebugLevel; l <= zapcore.FatalLevel; l++ {
+		s, ok := levelSampling[l]
+		if !ok || s != nil && (s.Initial < 0 || s.Thereafter < 0) {
+			s = sampling
+		}
+		if s != nil {
+			samplers[l] = zapcore.NewSamplerWithOptions(core, time.Second, s.Initial, s.Thereafter)
+		}
+	}
+	return &levelSamplerCore{Core: core, samplers: samplers}
+}
+
+func (c *levelSamplerCore) With(fields []zapcore.Field) zapcore.Core {
+	samplers := make(map[zapcore.Level]zapcore.Core, len(c.samplers))
+	for l, sampler := range c.samplers {
+		samplers[l] = sampler.With(fields)
+	}
+	return &levelSamplerCore{Core: c.Core.With(fields), samplers: samplers}
+}
+
+func (c *levelSamplerCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if sampler, ok := c.samplers[entry.Level]; ok {
+		return sampler.Check(entry, checked)
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// wrapCore installs the configured hooks beneath the sampler, so sampled out entries never reach them.
+func wrapCore(config Configuration) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if config.routingField != "" {
+			routes := make(map[string]zapcore.Core, len(config.routes))
+			for route, routeCore := range config.routes {
+				if routeCore != nil {
+					routes[route] = routeCore
+				}
+			}
+			core = NewRoutingCore(config.routingField, core, routes)
+		}
+		if config.otlpExporter != nil {
+			core = zapcore.NewTee(core, newOTLPCore(config.otlpExporter, core))
+		}
+		// the sequence numbers only the entries of the primary outputs, so those a sink alone takes leave no gaps
+		if config.sequence {
+			core = &hookedCore{Core: core, hooks: []entryHook{sequenceHook}}
+		}
+		tee := []zapcore.Core{&levelCheckedCore{Core: core}, &sinkCore{}}
+		for _, extra := range config.cores {
+			if extra != nil {
+				tee = append(tee, &levelCheckedCore{Core: extra})
+			}
+		}
+		core = zapcore.NewTee(tee...)
+		if hooks := buildHooks(config); len(hooks) > 0 {
+			core = &hookedCore{Core: core, hooks: hooks}
+		}
+		if config.escapeControlChars {
+			core = &controlCharacterCore{Core: core}
+		}
+		if config.marshalFailureMode > MarshalFailureDefault && config.marshalFailureMode <= MarshalFailureWarn {
+			core = &marshalFailureCore{Core: core, mode: config.marshalFailureMode}
+		}
+		core = &encoderCore{Core: core}
+		core = newRedactionCore(core)
+		if len(config.levelSampling) > 0 {
+			return newLevelSamplerCore(core, samplingConfig(config), config.levelSampling)
+		}
+		if sampling := samplingConfig(config); sampling != nil {
+			core = zapcore.NewSamplerWithOptions(core, time.Second, sampling.Initial, sampling.Thereafter)
+		}
+		return core
+	})
+}
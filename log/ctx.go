@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+)
+
+// The ...Ctx functions log with the trace fields of ctx, read at call time, and the fields attached to ctx through
+// ContextWith, leaving the package logger untouched. Without a trace in ctx the line carries no trace fields.
+
+func DebugCtx(ctx context.Context, template string, args ...interface{}) {
+	defaultInstance().DebugCtx(ctx, template, args...)
+}
+
+func InfoCtx(ctx context.Context, template string, args ...interface{}) {
+	defaultInstance().InfoCtx(ctx, template, args...)
+}
+
+func WarnCtx(ctx context.Context, template string, args ...interface{}) {
+	defaultInstance().WarnCtx(ctx, template, args...)
+}
+
+func ErrorCtx(ctx context.Context, template string, args ...interface{}) {
+	defaultInstance().ErrorCtx(ctx, template, args...)
+}
+
+func DebugWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	defaultInstance().DebugWCtx(ctx, msg, keysAndValues...)
+}
+
+func InfoWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	defaultInstance().InfoWCtx(ctx, msg, keysAndValues...)
+}
+
+func WarnWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	defaultInstance().WarnWCtx(ctx, msg, keysAndValues...)
+}
+
+func ErrorWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	defaultInstance().ErrorWCtx(ctx, msg, keysAndValues...)
+}
@@ -0,0 +1,45 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCtxFunctionsAttachTraceIdsPerLine(t *testing.T) {
+	// GIVEN
+	ctx := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("DEBUG"), func() {
+		log.InfoCtx(ctx, "booking %s confirmed", "ABC123")
+		log.Info("without trace")
+		log.ErrorWCtx(ctx, "payment failed", "Body.payment.provider", "adyen")
+	})
+	// THEN
+	require.Len(t, entries, 3)
+	assert.Equal(t, "booking ABC123 confirmed", entries[0][log.Message])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entries[0][log.TraceId])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entries[0][log.CorrelationId])
+	assert.Equal(t, "00f067aa0ba902b7", entries[0][log.SpanId])
+	assert.NotContains(t, entries[1], log.TraceId)
+	assert.Equal(t, "ERROR", entries[2][log.Level])
+	assert.Equal(t, "00f067aa0ba902b7", entries[2][log.SpanId])
+	assert.Equal(t, "adyen", entries[2]["Body.payment.provider"])
+}
+
+func TestCtxFunctionsWithoutSpanContext(t *testing.T) {
+	// GIVEN
+	traced := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("DEBUG"), func() {
+		log.SetupTraceIds(traced)
+		log.WarnCtx(context.Background(), "no trace")
+		log.DebugWCtx(context.Background(), "no trace either", "key", "value")
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	assert.NotContains(t, entries[0], log.TraceId)
+	assert.NotContains(t, entries[1], log.SpanId)
+}
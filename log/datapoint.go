@@ -0,0 +1,29 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var seriesNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.:]*$`)
+
+// DataPoint logs a single time-series sample using a fixed set of fields, so the line can be ingested by a TSDB.
+// The timestamp is emitted in epoch milliseconds. The series name must start with a letter or underscore
+// and contain only letters, digits, underscores, dots and colons.
+func DataPoint(ctx context.Context, series string, ts time.Time, value float64, tags map[string]string) error {
+	if !seriesNamePattern.MatchString(series) {
+		return fmt.Errorf("invalid data point series name: %q", series)
+	}
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	loggerFromContext(ctx).Infow("DataPoint",
+		DataPointSeries, series,
+		DataPointTimestamp, ts.UnixMilli(),
+		DataPointValue, value,
+		DataPointTags, tags)
+	return nil
+}
@@ -0,0 +1,37 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDataPoint(t *testing.T) {
+	// GIVEN
+	ts := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	// WHEN
+	var err error
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		err = log.DataPoint(context.Background(), "orders.placed", ts, 12.5, map[string]string{"market": "IE"})
+	})
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "orders.placed", entries[0][log.DataPointSeries])
+	assert.Equal(t, float64(ts.UnixMilli()), entries[0][log.DataPointTimestamp])
+	assert.Equal(t, 12.5, entries[0][log.DataPointValue])
+	assert.Equal(t, map[string]interface{}{"market": "IE"}, entries[0][log.DataPointTags])
+}
+
+func TestDataPointRejectsInvalidSeries(t *testing.T) {
+	// WHEN
+	var err error
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		err = log.DataPoint(context.Background(), "orders placed!", time.Now(), 1, nil)
+	})
+	// THEN
+	assert.Error(t, err)
+	assert.Empty(t, entries)
+}
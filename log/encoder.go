@@ -0,0 +1,72 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"reflect"
+	"sync"
+)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[reflect.Type]func(v interface{}) interface{}{}
+)
+
+// RegisterEncoder makes values of type t logged under the value fn returns for them, giving a domain type
+// such as an id or an amount a canonical representation in place of the reflection based default.
+func RegisterEncoder(t reflect.Type, fn func(v interface{}) interface{}) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[t] = fn
+}
+
+// encoderCore replaces the values of fields of a registered type before they reach the encoder,
+// attached through With included.
+type encoderCore struct {
+	zapcore.Core
+}
+
+func (c *encoderCore) With(fields []zapcore.Field) zapcore.Core {
+	return &encoderCore{Core: c.Core.With(encodeFields(fields))}
+}
+
+func (c *encoderCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *encoderCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, encodeFields(fields))
+}
+
+func encodeFields(fields []zapcore.Field) []zapcore.Field {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	if len(encoders) == 0 {
+		return fields
+	}
+
+	var encoded []zapcore.Field
+	for i, field := range fields {
+		var fn func(v interface{}) interface{}
+		if field.Interface != nil {
+			fn = encoders[reflect.TypeOf(field.Interface)]
+		}
+		if fn == nil {
+			if encoded != nil {
+				encoded = append(encoded, field)
+			}
+			continue
+		}
+		if encoded == nil {
+			encoded = append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+		}
+		encoded = append(encoded, zap.Any(field.Key, fn(field.Interface)))
+	}
+	if encoded == nil {
+		return fields
+	}
+	return encoded
+}
@@ -0,0 +1,39 @@
+package log_test
+
+import (
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"testing"
+)
+
+type fareAmount struct {
+	minorUnits int64
+	currency   string
+}
+
+type seat [2]byte
+
+func TestRegisterEncoder(t *testing.T) {
+	// GIVEN
+	log.RegisterEncoder(reflect.TypeOf(fareAmount{}), func(v interface{}) interface{} {
+		f := v.(fareAmount)
+		return fmt.Sprintf("%d.%02d %s", f.minorUnits/100, f.minorUnits%100, f.currency)
+	})
+	log.RegisterEncoder(reflect.TypeOf(seat{}), func(v interface{}) interface{} {
+		s := v.(seat)
+		return fmt.Sprintf("%d%c", s[0], s[1])
+	})
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.With("Body.seat", seat{12, 'C'})
+		log.InfoW("Fare quoted", "Body.fare", fareAmount{minorUnits: 4999, currency: "EUR"}, "Body.passengers", 2)
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "49.99 EUR", entries[0]["Body.fare"])
+	assert.Equal(t, "12C", entries[0]["Body.seat"])
+	assert.Equal(t, float64(2), entries[0]["Body.passengers"])
+}
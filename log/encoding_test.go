@@ -0,0 +1,93 @@
+package log_test
+
+import (
+	"encoding/json"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConsoleEncoding(t *testing.T) {
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO").WithEncoding(log.EncodingConsole), func() {
+		log.InfoW("Booking confirmed", "Body.booking.id", "ABC123")
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	assert.False(t, json.Valid([]byte(lines[0])), lines[0])
+	assert.Contains(t, lines[0], "Booking confirmed")
+	assert.Contains(t, lines[0], "ABC123")
+}
+
+func TestJSONEncodingByDefault(t *testing.T) {
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.Info("Booking confirmed")
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	assert.True(t, json.Valid([]byte(lines[0])), lines[0])
+}
+
+func TestConsoleEncodingFromEnvironment(t *testing.T) {
+	// GIVEN
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "")
+	t.Setenv(log.EncodingEnv, "console")
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.Info("Booking confirmed")
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	assert.False(t, json.Valid([]byte(lines[0])), lines[0])
+	assert.Regexp(t, `^\d{2}:\d{2}:\d{2}\.\d{3}\s`, lines[0])
+}
+
+func TestEncodingEnvironmentIgnoredInLambda(t *testing.T) {
+	// GIVEN
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "test-function")
+	t.Setenv(log.EncodingEnv, "console")
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.Info("Booking confirmed")
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	assert.True(t, json.Valid([]byte(lines[0])), lines[0])
+}
+
+func TestExplicitEncodingOverEnvironment(t *testing.T) {
+	// GIVEN
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "")
+	t.Setenv(log.EncodingEnv, "console")
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO").WithEncoding(log.EncodingJSON), func() {
+		log.Info("Booking confirmed")
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	assert.True(t, json.Valid([]byte(lines[0])), lines[0])
+}
+
+func TestValidateConfigurationRejectsEncodingEnvironment(t *testing.T) {
+	// GIVEN
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "")
+	t.Setenv(log.EncodingEnv, "pretty")
+	// WHEN
+	err := log.ValidateConfiguration(testConfiguration("INFO"))
+	// THEN
+	assert.ErrorContains(t, err, `unknown log encoding "pretty"`)
+}
+
+func TestInitEFallsBackToJSONOnUnknownEncoding(t *testing.T) {
+	// GIVEN
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "")
+	t.Setenv(log.EncodingEnv, "pretty")
+	log.ResetLogger()
+	// WHEN
+	err := log.InitE(testConfiguration("INFO"))
+	// THEN
+	assert.ErrorContains(t, err, `unknown log encoding "pretty"`)
+	assert.NotPanics(t, func() { log.Info("logged to stderr") })
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// codedError is implemented by the application errors carrying an error code.
+type codedError interface {
+	Code() string
+}
+
+// LogError logs msg at ERROR with the ErrorMessage of err, the ErrorCode of the first error in its chain carrying one
+// and, for errors formatting their stack with %+v like github.com/pkg/errors ones, the ErrorStack. err is recorded
+// on the span of ctx too, as frotel.RecordError does. A nil err logs nothing.
+func LogError(ctx context.Context, err error, msg string, keysAndValues ...interface{}) {
+	defaultInstance().LogError(ctx, err, msg, keysAndValues...)
+}
+
+// LogError is the package LogError logging through l.
+func (l *Instance) LogError(ctx context.Context, err error, msg string, keysAndValues ...interface{}) {
+	if err == nil {
+		return
+	}
+	fields := []interface{}{ErrorMessage, err.Error()}
+	var coded codedError
+	if errors.As(err, &coded) {
+		fields = append(fields, ErrorCode, coded.Code())
+	}
+	var formatter fmt.Formatter
+	if errors.As(err, &formatter) {
+		fields = append(fields, ErrorStack, fmt.Sprintf("%+v", formatter))
+	}
+	trace.SpanFromContext(ctx).RecordError(err)
+
+	logger, keysAndValues := l.ctxLogger(ctx, append(fields, keysAndValues...))
+	logger.Errorw(msg, keysAndValues...)
+}
@@ -0,0 +1,89 @@
+package log_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+type bookingError struct {
+	code string
+}
+
+func (e bookingError) Error() string {
+	return "booking rejected"
+}
+
+func (e bookingError) Code() string {
+	return e.code
+}
+
+// stackError formats its stack with %+v, like the errors of github.com/pkg/errors.
+type stackError struct {
+	error
+}
+
+func (e stackError) Unwrap() error {
+	return e.error
+}
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s\nmain.book\n\t/app/book.go:42", e.Error())
+		return
+	}
+	fmt.Fprint(s, e.Error())
+}
+
+func TestLogErrorWithCodedError(t *testing.T) {
+	// GIVEN
+	recorder := tracetest.NewSpanRecorder()
+	ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).
+		Tracer("test").Start(context.Background(), "booking")
+	err := fmt.Errorf("confirming booking: %w", stackError{bookingError{code: "BOOKING_FULL"}})
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.LogError(ctx, err, "Booking failed", "Body.booking.id", "B-1")
+	})
+	span.End()
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ERROR", entries[0][log.Level])
+	assert.Equal(t, "Booking failed", entries[0][log.Message])
+	assert.Equal(t, "confirming booking: booking rejected", entries[0][log.ErrorMessage])
+	assert.Equal(t, "BOOKING_FULL", entries[0][log.ErrorCode])
+	assert.Contains(t, entries[0][log.ErrorStack], "/app/book.go:42")
+	assert.Equal(t, "B-1", entries[0]["Body.booking.id"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), entries[0][log.TraceId])
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+func TestLogErrorWithPlainError(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.LogError(context.Background(), errors.New("timeout"), "Payment failed")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "timeout", entries[0][log.ErrorMessage])
+	assert.NotContains(t, entries[0], log.ErrorCode)
+	assert.NotContains(t, entries[0], log.ErrorStack)
+}
+
+func TestLogErrorWithNilError(t *testing.T) {
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.LogError(context.Background(), nil, "Nothing happened")
+	})
+	// THEN
+	assert.Empty(t, lines)
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	ExpectName     = "Body.expect.name"
+	ExpectExpected = "Body.expect.expected"
+	ExpectActual   = "Body.expect.actual"
+	ExpectDiff     = "Body.expect.diff"
+)
+
+// Expect checks a soft invariant: it logs a warning carrying both values and their differences when actual
+// doesn't deeply equal expected, and does nothing otherwise.
+func Expect(ctx context.Context, name string, expected, actual interface{}) {
+	if reflect.DeepEqual(expected, actual) {
+		return
+	}
+	loggerFromContext(ctx).Warnw("Expectation not met",
+		ExpectName, name,
+		ExpectExpected, ToString(expected),
+		ExpectActual, ToString(actual),
+		ExpectDiff, diff(expected, actual))
+}
+
+// diff lists the paths at which the JSON representations of expected and actual differ.
+func diff(expected, actual interface{}) []string {
+	var differences []string
+	diffValues("$", normalize(expected), normalize(actual), &differences)
+	return differences
+}
+
+func normalize(value interface{}) interface{} {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(bytes, &normalized); err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return normalized
+}
+
+func diffValues(path string, expected, actual interface{}, differences *[]string) {
+	expectedMap, expectedIsMap := expected.(map[string]interface{})
+	actualMap, actualIsMap := actual.(map[string]interface{})
+	if expectedIsMap && actualIsMap {
+		keys := make(map[string]bool)
+		for key := range expectedMap {
+			keys[key] = true
+		}
+		for key := range actualMap {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			diffValues(path+"."+key, expectedMap[key], actualMap[key], differences)
+		}
+		return
+	}
+
+	expectedSlice, expectedIsSlice := expected.([]interface{})
+	actualSlice, actualIsSlice := actual.([]interface{})
+	if expectedIsSlice && actualIsSlice && len(expectedSlice) == len(actualSlice) {
+		for i := range expectedSlice {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), expectedSlice[i], actualSlice[i], differences)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*differences = append(*differences, fmt.Sprintf("%s: expected %s, got %s", path, ToString(expected), ToString(actual)))
+	}
+}
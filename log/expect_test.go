@@ -0,0 +1,43 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type fare struct {
+	Currency string
+	Amount   int
+	Legs     []string
+}
+
+func TestExpectWarnsOnMismatch(t *testing.T) {
+	// GIVEN
+	expected := fare{Currency: "EUR", Amount: 100, Legs: []string{"DUB-STN"}}
+	actual := fare{Currency: "EUR", Amount: 120, Legs: []string{"DUB-LTN"}}
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.Expect(context.Background(), "fare-total", expected, actual)
+	})
+	// THEN
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+	assert.Equal(t, "fare-total", entries[0][log.ExpectName])
+	assert.Equal(t, `{"Currency":"EUR","Amount":100,"Legs":["DUB-STN"]}`, entries[0][log.ExpectExpected])
+	assert.Equal(t, `{"Currency":"EUR","Amount":120,"Legs":["DUB-LTN"]}`, entries[0][log.ExpectActual])
+	assert.Equal(t, []interface{}{
+		`$.Amount: expected 100, got 120`,
+		`$.Legs[0]: expected "DUB-STN", got "DUB-LTN"`,
+	}, entries[0][log.ExpectDiff])
+}
+
+func TestExpectIsSilentOnMatch(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.Expect(context.Background(), "fare-total", fare{Amount: 100}, fare{Amount: 100})
+	})
+	// THEN
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,56 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"sync"
+)
+
+// ResetLogger drops the installed logger, as if Init had never been called.
+func ResetLogger() {
+	logMu.Lock()
+	defer logMu.Unlock()
+	log = nil
+	baseLog = nil
+	initLog = nil
+	std = nil
+	defaultLogOnce = sync.Once{}
+}
+
+// ResetColdStart makes the next SetupLambdaContext call a cold start.
+func ResetColdStart() {
+	warmStart.Store(false)
+}
+
+// CountXRaySetups counts the X-Ray setups Init runs from now on, until restore is called.
+func CountXRaySetups() (count func() int, restore func()) {
+	var mu sync.Mutex
+	setups := 0
+	previous := xraySetup
+	xraySetup = func(log *zap.SugaredLogger) {
+		mu.Lock()
+		defer mu.Unlock()
+		setups++
+	}
+	count = func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return setups
+	}
+	return count, func() { xraySetup = previous }
+}
+
+// ResetRedaction drops the redacted keys and patterns.
+func ResetRedaction() {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redactedKeys = nil
+	redactedPatterns = nil
+	redactGeneration.Add(1)
+}
+
+// ResetPromotion drops the keys promoted to spans.
+func ResetPromotion() {
+	promotedMu.Lock()
+	defer promotedMu.Unlock()
+	promotedKeys = map[string]bool{}
+}
@@ -0,0 +1,95 @@
+package log_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFatalW(t *testing.T) {
+	if path := os.Getenv("GOFRLIB_FATAL_LOG"); path != "" {
+		log.Init(testConfiguration("INFO").WithOutputPaths(path))
+		log.FatalW("Missing required env var", "Body.env", "TABLE_NAME")
+		return
+	}
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "fatal.log")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalW$")
+	cmd.Env = append(os.Environ(), "GOFRLIB_FATAL_LOG="+path)
+	// WHEN
+	err := cmd.Run()
+	// THEN
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "process should exit with an error, got %v", err)
+	assert.Equal(t, 1, exitErr.ExitCode())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(content))), &entry))
+	assert.Equal(t, "FATAL", entry[log.Level])
+	assert.Equal(t, "Missing required env var", entry[log.Message])
+	assert.Equal(t, "TABLE_NAME", entry["Body.env"])
+	assert.Equal(t, "test-application", entry[log.Application])
+}
+
+func TestPanicW(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		assert.PanicsWithValue(t, "Corrupted state", func() {
+			log.PanicW("Corrupted state", "Body.booking.id", "ABC123")
+		})
+		assert.Panics(t, func() { log.Panic("unexpected %s", "state") })
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	assert.Equal(t, "PANIC", entries[0][log.Level])
+	assert.Equal(t, "ABC123", entries[0]["Body.booking.id"])
+	assert.Equal(t, "unexpected state", entries[1][log.Message])
+}
+
+func TestPanicFlushes(t *testing.T) {
+	// GIVEN
+	synced := 0
+	core := syncCore{LevelEnabler: zapcore.InfoLevel, sync: func() error {
+		synced++
+		return nil
+	}}
+	// WHEN
+	_ = captureOutput(t, testConfiguration("INFO").WithCores(core), func() {
+		before := synced
+		assert.Panics(t, func() { log.PanicW("Corrupted state") })
+		// THEN
+		assert.Equal(t, before+1, synced)
+	})
+}
+
+func TestDPanicW(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		assert.NotPanics(t, func() {
+			log.DPanicW("Unexpected state", "Body.booking.id", "ABC123")
+		})
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "DPANIC", entries[0][log.Level])
+	assert.Equal(t, "ABC123", entries[0]["Body.booking.id"])
+}
+
+func TestDPanicInDevMode(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithDevMode(true), func() {
+		assert.PanicsWithValue(t, "unexpected state", func() { log.DPanic("unexpected %s", "state") })
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "DPANIC", entries[0][log.Level])
+}
@@ -0,0 +1,48 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"os"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.DebugFields("Dropped", zap.String("Body.booking.id", "B-0"))
+		log.InfoFields("Booking loaded", zap.String("Body.booking.id", "B-1"), zap.Int("Body.booking.seats", 2))
+		log.WarnFields("Booking delayed", zap.Bool("Body.booking.paid", false))
+		log.ErrorFields("Booking failed", zap.Int64("Body.booking.attempt", 3))
+	})
+	// THEN
+	require.Len(t, entries, 3)
+	assert.Equal(t, "INFO", entries[0][log.Level])
+	assert.Equal(t, "B-1", entries[0]["Body.booking.id"])
+	assert.Equal(t, float64(2), entries[0]["Body.booking.seats"])
+	assert.Equal(t, "log/fields_test.go:16", entries[0][log.Logger])
+	assert.Equal(t, false, entries[1]["Body.booking.paid"])
+	assert.Equal(t, "ERROR", entries[2][log.Level])
+	assert.Equal(t, float64(3), entries[2]["Body.booking.attempt"])
+}
+
+func BenchmarkInfoW(b *testing.B) {
+	log.Init(testConfiguration("INFO").WithOutputPaths(os.DevNull).WithoutSampling())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.InfoW("Record processed", "Body.record.id", "R-1", "Body.record.shard", 7, "Body.record.retried", false)
+	}
+}
+
+func BenchmarkInfoFields(b *testing.B) {
+	log.Init(testConfiguration("INFO").WithOutputPaths(os.DevNull).WithoutSampling())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.InfoFields("Record processed",
+			zap.String("Body.record.id", "R-1"), zap.Int("Body.record.shard", 7), zap.Bool("Body.record.retried", false))
+	}
+}
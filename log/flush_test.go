@@ -0,0 +1,75 @@
+package log_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// syncCore is a core doing nothing but syncing through sync.
+type syncCore struct {
+	zapcore.LevelEnabler
+	sync func() error
+}
+
+func (c syncCore) With([]zapcore.Field) zapcore.Core { return c }
+
+func (c syncCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked
+}
+
+func (c syncCore) Write(zapcore.Entry, []zapcore.Field) error { return nil }
+
+func (c syncCore) Sync() error { return c.sync() }
+
+func TestFlushCtxReturnsWhenContextIsDone(t *testing.T) {
+	// GIVEN
+	log.Init(testConfiguration("INFO"))
+	release := make(chan struct{})
+	defer close(release)
+	restore := log.SetTestCore(syncCore{LevelEnabler: zapcore.InfoLevel, sync: func() error {
+		<-release
+		return nil
+	}})
+	defer restore()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// WHEN
+	start := time.Now()
+	err := log.FlushCtx(ctx)
+	// THEN
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestFlushCtxIgnoresBenignSyncErrors(t *testing.T) {
+	// GIVEN
+	log.Init(testConfiguration("INFO"))
+	restore := log.SetTestCore(syncCore{LevelEnabler: zapcore.InfoLevel, sync: func() error {
+		return &os.PathError{Op: "sync", Path: "/dev/stderr", Err: syscall.EINVAL}
+	}})
+	defer restore()
+	// WHEN
+	err := log.FlushCtx(context.Background())
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestFlushCtxReturnsSyncErrors(t *testing.T) {
+	// GIVEN
+	log.Init(testConfiguration("INFO"))
+	restore := log.SetTestCore(syncCore{LevelEnabler: zapcore.InfoLevel, sync: func() error {
+		return fmt.Errorf("disk full")
+	}})
+	defer restore()
+	// WHEN
+	err := log.FlushCtx(context.Background())
+	// THEN
+	assert.EqualError(t, err, "disk full")
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"os"
+)
+
+// Format selects the field layout of the emitted entries.
+type Format string
+
+const (
+	// FormatDefault is the layout shared with the ec2 application loggers.
+	FormatDefault Format = ""
+	// FormatGCP follows the Google Cloud Logging structured logging conventions.
+	FormatGCP Format = "gcp"
+)
+
+const (
+	GCPTimestamp    = "time"
+	GCPSeverity     = "severity"
+	GCPMessage      = "message"
+	GCPTrace        = "logging.googleapis.com/trace"
+	GCPSpanId       = "logging.googleapis.com/spanId"
+	GCPTraceSampled = "logging.googleapis.com/trace_sampled"
+)
+
+func encoderConfig(config Configuration) zapcore.EncoderConfig {
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        Timestamp,
+		LevelKey:       Level,
+		NameKey:        "logger",
+		CallerKey:      Logger,
+		MessageKey:     Message,
+		StacktraceKey:  StackTrace,
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeTime:     timeEncoder(config),
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+	if config.resolveEncoding() == EncodingConsole {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	if config.format == FormatGCP {
+		encoderConfig.TimeKey = GCPTimestamp
+		encoderConfig.LevelKey = GCPSeverity
+		encoderConfig.MessageKey = GCPMessage
+		encoderConfig.EncodeLevel = gcpSeverityEncoder
+	}
+	return encoderConfig
+}
+
+// gcpSeverityEncoder maps zap levels to the LogSeverity names understood by Cloud Logging.
+func gcpSeverityEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch level {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.FatalLevel:
+		enc.AppendString("ALERT")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
+
+// gcpTraceFields returns the trace correlation fields Cloud Logging links to Cloud Trace with.
+func gcpTraceFields(projectID, traceId, spanId string, sampled bool) []interface{} {
+	if projectID == "" {
+		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	return []interface{}{
+		GCPTrace, fmt.Sprintf("projects/%s/traces/%s", projectID, traceId),
+		GCPSpanId, spanId,
+		GCPTraceSampled, sampled,
+	}
+}
@@ -0,0 +1,65 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestGCPFormat(t *testing.T) {
+	// GIVEN
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	config := testConfiguration("DEBUG").WithFormat(log.FormatGCP).WithGCPProjectID("test-project")
+
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		log.SetupTraceIds(ctx)
+		log.Warn("seat map unavailable")
+	})
+
+	// THEN
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "WARNING", entry[log.GCPSeverity])
+	assert.Equal(t, "seat map unavailable", entry[log.GCPMessage])
+	_, err := time.Parse(time.RFC3339Nano, entry[log.GCPTimestamp].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, "projects/test-project/traces/4bf92f3577b34da6a3ce929d0e0e4736", entry[log.GCPTrace])
+	assert.Equal(t, "00f067aa0ba902b7", entry[log.GCPSpanId])
+	assert.Equal(t, true, entry[log.GCPTraceSampled])
+	assert.NotContains(t, entry, log.Level)
+	assert.NotContains(t, entry, log.Message)
+	assert.NotContains(t, entry, log.TraceId)
+}
+
+func TestGCPFormatProjectFromEnv(t *testing.T) {
+	// GIVEN
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "env-project")
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId,
+		SpanID:  spanId,
+	}))
+
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithFormat(log.FormatGCP), func() {
+		log.SetupTraceIds(ctx)
+		log.Info("boarding started")
+	})
+
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "INFO", entries[0][log.GCPSeverity])
+	assert.Equal(t, "projects/env-project/traces/4bf92f3577b34da6a3ce929d0e0e4736", entries[0][log.GCPTrace])
+}
@@ -0,0 +1,56 @@
+package log
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+const (
+	heartbeatUptime     = "Body.heartbeat.uptimeSeconds"
+	heartbeatHeapAlloc  = "Body.heartbeat.memory.heapAllocBytes"
+	heartbeatSys        = "Body.heartbeat.memory.sysBytes"
+	heartbeatGoroutines = "Body.heartbeat.goroutines"
+)
+
+var processStart = time.Now()
+
+// Heartbeat logs a liveness entry every interval until stop is called or ctx is done.
+// Silence in the heartbeat lines of a running instance points at a stuck process.
+// An interval that isn't positive logs a warning and starts nothing, stop is then a no-op.
+func Heartbeat(ctx context.Context, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		Warn("heartbeat not started, non positive interval %s", interval)
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				logHeartbeat()
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func logHeartbeat() {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	InfoW("Heartbeat",
+		heartbeatUptime, int64(time.Since(processStart).Seconds()),
+		heartbeatHeapAlloc, mem.HeapAlloc,
+		heartbeatSys, mem.Sys,
+		heartbeatGoroutines, runtime.NumGoroutine())
+}
@@ -0,0 +1,50 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		stop := log.Heartbeat(context.Background(), 5*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		stop()
+	})
+	// THEN
+	assert.NotEmpty(t, entries)
+	assert.Equal(t, "Heartbeat", entries[0][log.Message])
+	assert.Contains(t, entries[0], "Body.heartbeat.uptimeSeconds")
+	assert.Contains(t, entries[0], "Body.heartbeat.memory.heapAllocBytes")
+}
+
+func TestHeartbeatStopsWithContext(t *testing.T) {
+	// GIVEN
+	ctx, cancel := context.WithCancel(context.Background())
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		stop := log.Heartbeat(ctx, time.Hour)
+		cancel()
+		stop()
+	})
+	// THEN
+	assert.Empty(t, entries)
+}
+
+func TestHeartbeatRejectsNonPositiveInterval(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		assert.NotPanics(t, func() {
+			log.Heartbeat(context.Background(), 0)()
+			log.Heartbeat(context.Background(), -time.Second)()
+		})
+	})
+	// THEN
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+	assert.Equal(t, "heartbeat not started, non positive interval 0s", entries[0][log.Message])
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/aws/aws-lambda-go/events"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"strings"
@@ -15,6 +17,10 @@ var blackListHeader = map[string]bool{
 	"fr-token-sig":  true,
 }
 
+const responseStatusKey = "Body.context.origin.response.status"
+
+var httpStatusCodeKey = attribute.Key("http.status_code")
+
 type headerItems []headerItem
 type headerItem struct {
 	name  string
@@ -86,3 +92,20 @@ func buildQueryParam(request events.APIGatewayProxyRequest) string {
 	}
 	return strings.Join(params, "&")
 }
+
+// HTTPStatus logs msg at the level matching the response status: info for 1xx-3xx, warn for 4xx and error for 5xx,
+// and records the status as http.status_code on the current span.
+func HTTPStatus(ctx context.Context, status int, msg string, keysAndValues ...interface{}) {
+	trace.SpanFromContext(ctx).SetAttributes(httpStatusCodeKey.Int(status))
+
+	keysAndValues = append([]interface{}{responseStatusKey, status}, expandKeysAndValues(keysAndValues)...)
+	logger := loggerFromContext(ctx)
+	switch {
+	case status >= 500:
+		logger.Errorw(msg, keysAndValues...)
+	case status >= 400:
+		logger.Warnw(msg, keysAndValues...)
+	default:
+		logger.Infow(msg, keysAndValues...)
+	}
+}
@@ -0,0 +1,22 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	for status, level := range map[int]string{200: "INFO", 302: "INFO", 404: "WARN", 429: "WARN", 500: "ERROR", 503: "ERROR"} {
+		// WHEN
+		entries := captureOutput(t, testConfiguration("INFO"), func() {
+			log.HTTPStatus(context.Background(), status, "Request handled", "route", "/bookings")
+		})
+		// THEN
+		assert.Len(t, entries, 1)
+		assert.Equal(t, level, entries[0][log.Level], "status %d", status)
+		assert.Equal(t, float64(status), entries[0]["Body.context.origin.response.status"])
+		assert.Equal(t, "/bookings", entries[0]["route"])
+	}
+}
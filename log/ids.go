@@ -0,0 +1,23 @@
+package log
+
+import (
+	"context"
+)
+
+// GetTraceID returns the W3C trace id of ctx, taken from the span context or, failing that, the X-Ray header,
+// and "" when ctx carries neither.
+func GetTraceID(ctx context.Context) string {
+	traceId, _, _, _ := traceIdentity(ctx)
+	return traceId
+}
+
+// GetSpanID returns the span id of ctx, the X-Ray parent id when it comes from the X-Ray header, or "".
+func GetSpanID(ctx context.Context) string {
+	_, spanId, _, _ := traceIdentity(ctx)
+	return spanId
+}
+
+// GetCorrelationID returns the id logged as CorrelationId for ctx, which is its trace id.
+func GetCorrelationID(ctx context.Context) string {
+	return GetTraceID(ctx)
+}
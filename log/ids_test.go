@@ -0,0 +1,37 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIdsFromSpanContext(t *testing.T) {
+	// GIVEN
+	ctx := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	// THEN
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", log.GetTraceID(ctx))
+	assert.Equal(t, "00f067aa0ba902b7", log.GetSpanID(ctx))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", log.GetCorrelationID(ctx))
+}
+
+func TestIdsFromXRayHeader(t *testing.T) {
+	// GIVEN
+	ctx := context.WithValue(context.Background(), xray.LambdaTraceHeaderKey,
+		"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	// THEN
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", log.GetTraceID(ctx))
+	assert.Equal(t, "53995c3f42cd8ad8", log.GetSpanID(ctx))
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", log.GetCorrelationID(ctx))
+}
+
+func TestIdsWithoutTrace(t *testing.T) {
+	// GIVEN
+	ctx := context.Background()
+	// THEN
+	assert.Empty(t, log.GetTraceID(ctx))
+	assert.Empty(t, log.GetSpanID(ctx))
+	assert.Empty(t, log.GetCorrelationID(ctx))
+}
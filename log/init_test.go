@@ -0,0 +1,218 @@
+package log_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitEReturnsBuildFailure(t *testing.T) {
+	// GIVEN
+	log.ResetLogger()
+	config := testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "missing", "app.log"))
+	// WHEN
+	err := log.InitE(config)
+	// THEN
+	assert.ErrorContains(t, err, "building logger")
+	assert.NotPanics(t, func() { log.Info("logged to stderr") })
+}
+
+func TestInitEKeepsPreviousLoggerOnBuildFailure(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "app.log")
+	assert.NoError(t, log.InitE(testConfiguration("INFO").WithOutputPaths(path)))
+	// WHEN
+	err := log.InitE(testConfiguration("DEBUG").WithOutputPaths(filepath.Join(t.TempDir(), "missing", "app.log")))
+	// THEN
+	assert.Error(t, err)
+	assert.False(t, log.IsDebugEnabled())
+}
+
+func TestInitEReturnsMalformedLevel(t *testing.T) {
+	// WHEN
+	err := log.InitE(testConfiguration("VERBOSE"))
+	// THEN
+	assert.ErrorContains(t, err, "malformed log level: VERBOSE")
+	assert.True(t, log.IsInfoEnabled())
+	assert.False(t, log.IsDebugEnabled())
+}
+
+func TestInitEReturnsConfigurationProblems(t *testing.T) {
+	// WHEN
+	err := log.InitE(testConfiguration("INFO").WithFormat("splunk").WithMaxCustomAttributes(-1))
+	// THEN
+	assert.ErrorContains(t, err, `unknown log format "splunk"`)
+	assert.ErrorContains(t, err, "negative custom attributes limit -1")
+	assert.True(t, log.IsInfoEnabled())
+}
+
+func TestInitPrintsProblemsToStderr(t *testing.T) {
+	// GIVEN
+	file, err := os.CreateTemp(t.TempDir(), "stderr")
+	require.NoError(t, err)
+	defer file.Close()
+	stderr := os.Stderr
+	os.Stderr = file
+	defer func() { os.Stderr = stderr }()
+	// WHEN
+	log.Init(testConfiguration("VERBOSE").WithOutputPaths(filepath.Join(t.TempDir(), "app.log")))
+	// THEN
+	content, err := os.ReadFile(file.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "malformed log level: VERBOSE")
+}
+
+func TestLogBeforeInit(t *testing.T) {
+	// GIVEN
+	t.Setenv("OTEL_SERVICE_NAME", "test-service")
+	log.ResetLogger()
+	file, err := os.CreateTemp(t.TempDir(), "log")
+	require.NoError(t, err)
+	defer file.Close()
+	stderr := os.Stderr
+	os.Stderr = file
+	defer func() { os.Stderr = stderr }()
+
+	// WHEN
+	assert.NotPanics(t, func() {
+		log.DebugW("not logged", "key", "value")
+		log.Info("logged before Init")
+		log.WithCustomAttr("tenant", "FR")
+		log.ErrorW("logged with attributes", "key", "value")
+	})
+	_ = log.Flush()
+
+	// THEN
+	content, err := os.ReadFile(file.Name())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "INFO", entry[log.Level])
+	assert.Equal(t, "logged before Init", entry[log.Message])
+	assert.Equal(t, "test-service", entry[log.ResourceServiceName])
+}
+
+func TestInitWithStdoutOutput(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	stdoutFile, err := os.Create(filepath.Join(dir, "stdout"))
+	require.NoError(t, err)
+	defer stdoutFile.Close()
+	stderrFile, err := os.Create(filepath.Join(dir, "stderr"))
+	require.NoError(t, err)
+	defer stderrFile.Close()
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutFile, stderrFile
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	// WHEN
+	require.NoError(t, log.InitE(testConfiguration("INFO").WithOutputPaths(log.Stdout)))
+	log.Info("logged to stdout")
+	_ = log.Flush()
+
+	// THEN
+	out, err := os.ReadFile(stdoutFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "logged to stdout")
+	errOut, err := os.ReadFile(stderrFile.Name())
+	require.NoError(t, err)
+	assert.Empty(t, errOut)
+}
+
+func TestServiceNamePrecedence(t *testing.T) {
+	tests := map[string]struct {
+		env      string
+		config   log.Configuration
+		expected string
+	}{
+		"explicit over env": {
+			env:      "env-service",
+			config:   testConfiguration("INFO").WithServiceName("explicit-service"),
+			expected: "explicit-service",
+		},
+		"env over default": {
+			env:      "env-service",
+			config:   testConfiguration("INFO"),
+			expected: "env-service",
+		},
+		"composed default": {
+			config:   testConfiguration("INFO"),
+			expected: "test-project-group-test-project-test-application",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			t.Setenv("OTEL_SERVICE_NAME", test.env)
+			// WHEN
+			entries := captureOutput(t, test.config, func() {
+				log.Info("Service name")
+			})
+			// THEN
+			require.Len(t, entries, 1)
+			assert.Equal(t, test.expected, entries[0][log.ResourceServiceName])
+		})
+	}
+}
+
+func TestInitWithBaseFields(t *testing.T) {
+	// GIVEN
+	config := testConfiguration("INFO").
+		WithBaseFields(zap.String("Resource.region", "eu-west-1"), zap.String("Resource.stage", "prod")).
+		WithBaseFields(zap.String("Resource.commitSha", "5f3c2a1"))
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9},
+		SpanID:  trace.SpanID{0x0f},
+	}))
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		log.Info("Deployed")
+		log.SetupTraceIds(ctx)
+		log.Info("Traced")
+		log.ResetRequestFields()
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, "eu-west-1", entry["Resource.region"])
+		assert.Equal(t, "prod", entry["Resource.stage"])
+		assert.Equal(t, "5f3c2a1", entry["Resource.commitSha"])
+	}
+	assert.NotContains(t, entries[0], log.TraceId)
+	assert.Equal(t, "4bf90000000000000000000000000000", entries[1][log.TraceId])
+}
+
+func TestInitFallsBackFromConfigurationProblems(t *testing.T) {
+	// GIVEN
+	config := testConfiguration("INFO").
+		WithSampling(-1, 100).
+		WithMarshalFailureMode(42).
+		WithRouting("Body.audit", map[string]zapcore.Core{"yes": nil})
+	// WHEN
+	lines := captureLines(t, config, func() {
+		log.InfoW("Audited", "Body.audit", "yes", "Body.payload", map[string]interface{}{"callback": func() {}})
+	})
+	// THEN
+	var entries []map[string]interface{}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "{") {
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+			entries = append(entries, entry)
+		}
+	}
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Audited", entries[0][log.Message])
+	assert.Contains(t, entries[0], "Body.payloadError")
+}
@@ -0,0 +1,236 @@
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Instance is a logger configured like the package logger, with fields of its own, for a library which shouldn't
+// share the fields of the application it runs in. The name Logger is taken by the Resource.logger field constant.
+// An Instance is immutable, With and WithCustomAttr return derived instances, so it's safe for concurrent use.
+// The package functions delegate to the Instance of the package logger.
+type Instance struct {
+	config Configuration
+	level  zap.AtomicLevel
+	log    *zap.SugaredLogger
+	// packageLogger marks the Instance of the package logger, whose context loggers derive from the package ones.
+	packageLogger bool
+}
+
+// New builds an Instance from config, the way Init builds the package logger but returning the problems Init
+// falls back from, and leaves the package logger untouched.
+func New(config Configuration) (*Instance, error) {
+	configured, logLevel, err := build(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Instance{config: config, level: logLevel, log: configured}, nil
+}
+
+// With returns an Instance adding args to every entry.
+func (l *Instance) With(args ...interface{}) *Instance {
+	return &Instance{config: l.config, level: l.level, log: l.log.With(expandKeysAndValues(args)...)}
+}
+
+// WithCustomAttr returns an Instance adding key as a Body.<customAttributesPrefix>.<key> field to every entry,
+// or l with a warning when key is empty or collides with one of ReservedKeys.
+func (l *Instance) WithCustomAttr(key string, value interface{}) *Instance {
+	attr, err := customAttrField(l.config.customAttributesPrefix, key)
+	if err != nil {
+		l.log.Warnf("custom attribute %s dropped, %v", key, err)
+		return l
+	}
+	return &Instance{config: l.config, level: l.level, log: l.log.With(attr, value)}
+}
+
+// SugaredLogger returns the underlying logger, see the package level SugaredLogger.
+func (l *Instance) SugaredLogger() *zap.SugaredLogger {
+	if l.packageLogger {
+		return l.log.WithOptions(zap.AddCallerSkip(-2))
+	}
+	return l.log.WithOptions(zap.AddCallerSkip(-1))
+}
+
+// fromContext returns the logger of l with the trace, baggage and context fields of ctx, see LoggerFromContext.
+func (l *Instance) fromContext(ctx context.Context) *zap.SugaredLogger {
+	if l.packageLogger {
+		return loggerFromContext(ctx).WithOptions(zap.AddCallerSkip(1))
+	}
+	fields := append(append(traceFields(ctx), allowedBaggageFields(ctx, l.config.baggageKeys)...), contextFields(ctx)...)
+	if len(fields) == 0 {
+		return l.log
+	}
+	return l.log.With(fields...)
+}
+
+// SetLevel changes the level of l and of the instances derived from it.
+func (l *Instance) SetLevel(logLevel string) error {
+	lvl, err := levelOf(logLevel)
+	if err != nil {
+		return malformedLevelError(logLevel)
+	}
+	l.level.SetLevel(lvl)
+	return nil
+}
+
+// GetLevel returns the current level of l, e.g. "INFO".
+func (l *Instance) GetLevel() string {
+	return l.level.Level().CapitalString()
+}
+
+func (l *Instance) IsLevelEnabled(level zapcore.Level) bool {
+	return l.log.Desugar().Check(level, "") != nil
+}
+
+func (l *Instance) IsDebugEnabled() bool {
+	return l.IsLevelEnabled(zapcore.DebugLevel)
+}
+
+func (l *Instance) IsInfoEnabled() bool {
+	return l.IsLevelEnabled(zapcore.InfoLevel)
+}
+
+func (l *Instance) IsWarnEnabled() bool {
+	return l.IsLevelEnabled(zapcore.WarnLevel)
+}
+
+func (l *Instance) IsErrorEnabled() bool {
+	return l.IsLevelEnabled(zapcore.ErrorLevel)
+}
+
+func (l *Instance) Flush() error {
+	return l.log.Sync()
+}
+
+func (l *Instance) Debug(template string, args ...interface{}) {
+	l.log.Debugf(template, args...)
+}
+
+func (l *Instance) DebugW(msg string, keysAndValues ...interface{}) {
+	l.log.Debugw(msg, expandKeysAndValues(keysAndValues)...)
+}
+
+func (l *Instance) Info(template string, args ...interface{}) {
+	l.log.Infof(template, args...)
+}
+
+func (l *Instance) InfoW(msg string, keysAndValues ...interface{}) {
+	l.log.Infow(msg, expandKeysAndValues(keysAndValues)...)
+}
+
+func (l *Instance) Warn(template string, args ...interface{}) {
+	l.log.Warnf(template, args...)
+}
+
+func (l *Instance) WarnW(msg string, keysAndValues ...interface{}) {
+	l.log.Warnw(msg, expandKeysAndValues(keysAndValues)...)
+}
+
+func (l *Instance) Error(template string, args ...interface{}) {
+	l.log.Errorf(template, args...)
+}
+
+func (l *Instance) ErrorW(msg string, keysAndValues ...interface{}) {
+	l.log.Errorw(msg, expandKeysAndValues(keysAndValues)...)
+}
+
+func (l *Instance) DebugFields(msg string, fields ...zap.Field) {
+	l.log.Desugar().Debug(msg, fields...)
+}
+
+func (l *Instance) InfoFields(msg string, fields ...zap.Field) {
+	l.log.Desugar().Info(msg, fields...)
+}
+
+func (l *Instance) WarnFields(msg string, fields ...zap.Field) {
+	l.log.Desugar().Warn(msg, fields...)
+}
+
+func (l *Instance) ErrorFields(msg string, fields ...zap.Field) {
+	l.log.Desugar().Error(msg, fields...)
+}
+
+func (l *Instance) DebugLazy(msg string, fn func() []interface{}) {
+	if l.IsDebugEnabled() {
+		l.log.Debugw(msg, expandKeysAndValues(fn())...)
+	}
+}
+
+func (l *Instance) InfoLazy(msg string, fn func() []interface{}) {
+	if l.IsInfoEnabled() {
+		l.log.Infow(msg, expandKeysAndValues(fn())...)
+	}
+}
+
+func (l *Instance) DebugCtx(ctx context.Context, template string, args ...interface{}) {
+	l.fromContext(ctx).Debugf(template, args...)
+}
+
+func (l *Instance) InfoCtx(ctx context.Context, template string, args ...interface{}) {
+	l.fromContext(ctx).Infof(template, args...)
+}
+
+func (l *Instance) WarnCtx(ctx context.Context, template string, args ...interface{}) {
+	l.fromContext(ctx).Warnf(template, args...)
+}
+
+func (l *Instance) ErrorCtx(ctx context.Context, template string, args ...interface{}) {
+	l.fromContext(ctx).Errorf(template, args...)
+}
+
+func (l *Instance) DebugWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	logger, keysAndValues := l.ctxLogger(ctx, keysAndValues)
+	logger.Debugw(msg, keysAndValues...)
+}
+
+func (l *Instance) InfoWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	logger, keysAndValues := l.ctxLogger(ctx, keysAndValues)
+	logger.Infow(msg, keysAndValues...)
+}
+
+func (l *Instance) WarnWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	logger, keysAndValues := l.ctxLogger(ctx, keysAndValues)
+	logger.Warnw(msg, keysAndValues...)
+}
+
+func (l *Instance) ErrorWCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	logger, keysAndValues := l.ctxLogger(ctx, keysAndValues)
+	logger.Errorw(msg, keysAndValues...)
+}
+
+func (l *Instance) Fatal(template string, args ...interface{}) {
+	l.log.Fatalf(template, args...)
+}
+
+func (l *Instance) FatalW(msg string, keysAndValues ...interface{}) {
+	l.log.Fatalw(msg, expandKeysAndValues(keysAndValues)...)
+}
+
+func (l *Instance) Panic(template string, args ...interface{}) {
+	defer l.flushOnPanic()
+	l.log.Panicf(template, args...)
+}
+
+func (l *Instance) PanicW(msg string, keysAndValues ...interface{}) {
+	defer l.flushOnPanic()
+	l.log.Panicw(msg, expandKeysAndValues(keysAndValues)...)
+}
+
+func (l *Instance) DPanic(template string, args ...interface{}) {
+	defer l.flushOnPanic()
+	l.log.DPanicf(template, args...)
+}
+
+func (l *Instance) DPanicW(msg string, keysAndValues ...interface{}) {
+	defer l.flushOnPanic()
+	l.log.DPanicw(msg, expandKeysAndValues(keysAndValues)...)
+}
+
+// flushOnPanic flushes l on the way up of the panics raised by Panic and DPanic, zap only flushes before exiting.
+func (l *Instance) flushOnPanic() {
+	if r := recover(); r != nil {
+		_ = l.Flush()
+		panic(r)
+	}
+}
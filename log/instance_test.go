@@ -0,0 +1,139 @@
+package log_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readEntries(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestNew(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "library.log")
+	library, err := log.New(testConfiguration("INFO").WithOutputPaths(path))
+	require.NoError(t, err)
+	// WHEN
+	var entries []map[string]interface{}
+	packageEntries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.With("Body.application.field", "app")
+		scoped := library.With("Body.library.name", "pricing").WithCustomAttr("tenant", "FR")
+		scoped.InfoW("Fare computed", "Body.fare.amount", 19.99)
+		library.Debug("Dropped")
+		library.Error("Fare %s missing", "F-1")
+		log.Info("Package logger")
+	})
+	require.NoError(t, library.Flush())
+	entries = readEntries(t, path)
+	// THEN
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Fare computed", entries[0][log.Message])
+	assert.Equal(t, "pricing", entries[0]["Body.library.name"])
+	assert.Equal(t, "FR", entries[0]["Body.testprefix.tenant"])
+	assert.Equal(t, 19.99, entries[0]["Body.fare.amount"])
+	assert.Equal(t, "log/instance_test.go:40", entries[0][log.Logger])
+	assert.NotContains(t, entries[0], "Body.application.field")
+	assert.Equal(t, "ERROR", entries[1][log.Level])
+	assert.NotContains(t, entries[1], "Body.library.name")
+	require.Len(t, packageEntries, 1)
+	assert.NotContains(t, packageEntries[0], "Body.library.name")
+}
+
+func TestNewRejectsMalformedLevel(t *testing.T) {
+	// WHEN
+	library, err := log.New(testConfiguration("LOUD"))
+	// THEN
+	assert.Nil(t, library)
+	assert.ErrorContains(t, err, "malformed log level")
+}
+
+func TestInstanceSetLevel(t *testing.T) {
+	// GIVEN
+	library, err := log.New(testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "library.log")))
+	require.NoError(t, err)
+	derived := library.With("Body.library.name", "pricing")
+	// WHEN
+	require.NoError(t, library.SetLevel("DEBUG"))
+	// THEN
+	assert.True(t, derived.IsLevelEnabled(zapcore.DebugLevel))
+	assert.Error(t, library.SetLevel("LOUD"))
+}
+
+func TestInstanceContextAndTypedVariants(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "library.log")
+	library, err := log.New(testConfiguration("INFO").WithOutputPaths(path))
+	require.NoError(t, err)
+	ctx := log.ContextWith(spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"), "Body.request.id", "R-1")
+	// WHEN
+	library.InfoWCtx(ctx, "Fare computed", "Body.fare.amount", 19.99)
+	library.WarnFields("Fare stale", zap.Int("Body.fare.ageMinutes", 30))
+	library.DebugLazy("Dropped", func() []interface{} {
+		t.Fatal("fields built while debug is disabled")
+		return nil
+	})
+	library.LogError(ctx, errors.New("fare missing"), "Fare lookup failed")
+	require.NoError(t, library.Flush())
+	// THEN
+	entries := readEntries(t, path)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entries[0][log.TraceId])
+	assert.Equal(t, "R-1", entries[0]["Body.request.id"])
+	assert.Equal(t, "log/instance_test.go:88", entries[0][log.Logger])
+	assert.Equal(t, float64(30), entries[1]["Body.fare.ageMinutes"])
+	assert.Equal(t, "log/instance_test.go:89", entries[1][log.Logger])
+	assert.Equal(t, "fare missing", entries[2][log.ErrorMessage])
+	assert.Equal(t, "log/instance_test.go:94", entries[2][log.Logger])
+	assert.True(t, library.IsWarnEnabled())
+	assert.False(t, library.IsDebugEnabled())
+}
+
+func TestInstancePanicFlushes(t *testing.T) {
+	// GIVEN
+	synced := 0
+	core := syncCore{LevelEnabler: zapcore.InfoLevel, sync: func() error {
+		synced++
+		return nil
+	}}
+	library, err := log.New(testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "library.log")).WithCores(core))
+	require.NoError(t, err)
+	// WHEN
+	assert.PanicsWithValue(t, "Corrupted state", func() { library.PanicW("Corrupted state") })
+	// THEN
+	assert.Equal(t, 1, synced)
+}
+
+func TestNewRejectsWhatInitFallsBackFrom(t *testing.T) {
+	// WHEN
+	library, err := log.New(testConfiguration("INFO").WithFormat("splunk"))
+	// THEN
+	assert.Nil(t, library)
+	assert.ErrorContains(t, err, `unknown log format "splunk"`)
+}
+
+func TestNewInDevMode(t *testing.T) {
+	// GIVEN
+	library, err := log.New(testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "library.log")).WithDevMode(true))
+	require.NoError(t, err)
+	// WHEN / THEN
+	assert.Panics(t, func() { library.DPanic("unexpected %s", "state") })
+}
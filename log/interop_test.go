@@ -0,0 +1,25 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestZapLogger(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.ZapLogger().Info("from a library")
+		log.SugaredLogger().Infow("from another library", "driver", "pgx")
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, "test-application", entry[log.Application])
+		assert.Equal(t, "test-project", entry[log.Project])
+		assert.True(t, strings.HasPrefix(entry[log.Logger].(string), "log/interop_test.go:"), entry[log.Logger])
+	}
+	assert.Equal(t, "pgx", entries[1]["driver"])
+}
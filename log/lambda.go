@@ -0,0 +1,45 @@
+package log
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"sync/atomic"
+)
+
+const (
+	AWSRequestID = "AWSRequestID"
+	ColdStart    = "ColdStart"
+)
+
+var warmStart atomic.Bool
+
+type coldStartKey struct{}
+
+// SetupLambdaContext replaces the AWSRequestID and ColdStart fields of the package logger with those of the invocation
+// of ctx, leaving the trace fields set by SetupTraceIds in place. ColdStart is true on the first invocation
+// of the execution environment only. The returned ctx carries the fields for LoggerFromContext and the ...Ctx functions.
+// The level is refreshed along the way when WatchLevel is set up.
+func SetupLambdaContext(ctx context.Context) context.Context {
+	lc, ok := lambdacontext.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	coldStart := !warmStart.Swap(true)
+	fields := []interface{}{AWSRequestID, lc.AwsRequestID, ColdStart, coldStart}
+
+	logger()
+	logMu.Lock()
+	lambdaLogFields = fields
+	log = requestLog()
+	std = nil
+	logMu.Unlock()
+	refreshLevel(ctx)
+	return context.WithValue(ContextWith(ctx, fields...), coldStartKey{}, coldStart)
+}
+
+// IsColdStart reports the ColdStart field SetupLambdaContext set up for the invocation of ctx,
+// false when ctx didn't go through SetupLambdaContext.
+func IsColdStart(ctx context.Context) bool {
+	coldStart, _ := ctx.Value(coldStartKey{}).(bool)
+	return coldStart
+}
@@ -0,0 +1,53 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestSetupLambdaContext(t *testing.T) {
+	// GIVEN
+	log.ResetColdStart()
+	traced := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	first := lambdacontext.NewContext(traced, &lambdacontext.LambdaContext{AwsRequestID: "request-1"})
+	second := lambdacontext.NewContext(traced, &lambdacontext.LambdaContext{AwsRequestID: "request-2"})
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.SetupTraceIds(first)
+		assert.True(t, log.IsColdStart(log.SetupLambdaContext(first)))
+		log.Info("cold invocation")
+		log.SetupTraceIds(second)
+		ctx := log.SetupLambdaContext(second)
+		assert.False(t, log.IsColdStart(ctx))
+		log.Info("warm invocation")
+		log.InfoCtx(ctx, "warm invocation through ctx")
+	})
+	// THEN
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"AWSRequestID":"request-1"`)
+	assert.Contains(t, lines[0], `"ColdStart":true`)
+	assert.Contains(t, lines[0], `"TraceId":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	for _, line := range lines[1:] {
+		assert.Equal(t, 1, strings.Count(line, `"AWSRequestID"`), line)
+		assert.Equal(t, 1, strings.Count(line, `"TraceId"`), line)
+		assert.Contains(t, line, `"AWSRequestID":"request-2"`)
+		assert.Contains(t, line, `"ColdStart":false`)
+	}
+	assert.Contains(t, lines[2], `"SpanId":"00f067aa0ba902b7"`)
+}
+
+func TestSetupLambdaContextWithoutLambdaContext(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.SetupLambdaContext(context.Background())
+		log.Info("outside Lambda")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0], log.AWSRequestID)
+}
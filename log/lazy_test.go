@@ -0,0 +1,37 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDebugLazySkipsFieldsWhenDisabled(t *testing.T) {
+	// GIVEN
+	called := false
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.DebugLazy("Payload", func() []interface{} {
+			called = true
+			return []interface{}{"body", log.ToString(map[string]string{"id": "ABC123"})}
+		})
+	})
+	// THEN
+	assert.False(t, called)
+	assert.Empty(t, entries)
+}
+
+func TestLazyBuildsFieldsWhenEnabled(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("DEBUG"), func() {
+		log.DebugLazy("Payload", func() []interface{} { return []interface{}{"body", `{"id":"ABC123"}`} })
+		log.InfoLazy("Summary", func() []interface{} { return []interface{}{"count", 3} })
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	assert.Equal(t, "DEBUG", entries[0][log.Level])
+	assert.Equal(t, `{"id":"ABC123"}`, entries[0]["body"])
+	assert.Equal(t, "INFO", entries[1][log.Level])
+	assert.Equal(t, float64(3), entries[1]["count"])
+}
@@ -0,0 +1,136 @@
+package log_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+func TestSetLevel(t *testing.T) {
+	// GIVEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		assert.False(t, log.IsDebugEnabled())
+		log.Debug("dropped")
+		// WHEN
+		assert.NoError(t, log.SetLevel("DEBUG"))
+		// THEN
+		assert.True(t, log.IsDebugEnabled())
+		assert.Equal(t, "DEBUG", log.GetLevel())
+		log.Debug("logged")
+	})
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "logged", entries[0][log.Message])
+}
+
+func TestSetLevelRejectsMalformedLevel(t *testing.T) {
+	// GIVEN
+	_ = captureOutput(t, testConfiguration("WARN"), func() {
+		// WHEN
+		err := log.SetLevel("VERBOSE")
+		// THEN
+		assert.Error(t, err)
+		assert.Equal(t, "WARN", log.GetLevel())
+	})
+}
+
+func TestIsLevelEnabled(t *testing.T) {
+	// WHEN
+	log.Init(testConfiguration("WARN"))
+	// THEN
+	assert.True(t, log.IsErrorEnabled())
+	assert.True(t, log.IsWarnEnabled())
+	assert.False(t, log.IsInfoEnabled())
+	assert.False(t, log.IsDebugEnabled())
+	assert.True(t, log.IsLevelEnabled(zapcore.ErrorLevel))
+	assert.False(t, log.IsLevelEnabled(zapcore.DebugLevel))
+}
+
+func TestInitLevels(t *testing.T) {
+	tests := map[string]struct {
+		level    string
+		expected string
+	}{
+		"empty":      {level: "", expected: "INFO"},
+		"whitespace": {level: "  ", expected: "INFO"},
+		"warning":    {level: "WARNING", expected: "WARN"},
+		"lower case": {level: "warning", expected: "WARN"},
+		"padded":     {level: " debug ", expected: "DEBUG"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// WHEN
+			err := log.InitE(testConfiguration(test.level))
+			// THEN
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, log.GetLevel())
+		})
+	}
+}
+
+func TestInitRejectsGarbageLevel(t *testing.T) {
+	// WHEN
+	err := log.InitE(testConfiguration("LOUD"))
+	// THEN
+	assert.ErrorContains(t, err, "malformed log level: LOUD")
+	assert.Equal(t, "INFO", log.GetLevel())
+}
+
+func TestWatchLevel(t *testing.T) {
+	// GIVEN
+	var reads int
+	source := func(context.Context) (string, error) {
+		reads++
+		return "DEBUG", nil
+	}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "request"})
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		stop := log.WatchLevel(source, 2)
+		defer stop()
+		// WHEN
+		log.SetupLambdaContext(ctx)
+		log.Debug("dropped")
+		log.SetupLambdaContext(ctx)
+		log.Debug("logged")
+	})
+	// THEN
+	assert.Equal(t, 1, reads)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "logged", entries[0][log.Message])
+}
+
+func TestWatchLevelReadsEnvironment(t *testing.T) {
+	// GIVEN
+	t.Setenv("LOG_LEVEL", "error")
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "request"})
+	_ = captureOutput(t, testConfiguration("INFO"), func() {
+		stop := log.WatchLevel(nil, 1)
+		defer stop()
+		// WHEN
+		log.SetupLambdaContext(ctx)
+		// THEN
+		assert.Equal(t, "ERROR", log.GetLevel())
+	})
+}
+
+func TestWatchLevelKeepsLevelOnFailure(t *testing.T) {
+	// GIVEN
+	source := func(context.Context) (string, error) {
+		return "", errors.New("parameter not found")
+	}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "request"})
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		stop := log.WatchLevel(source, 1)
+		defer stop()
+		// WHEN
+		log.SetupLambdaContext(ctx)
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "log level not refreshed, parameter not found", entries[0][log.Message])
+	assert.Equal(t, "INFO", log.GetLevel())
+}
@@ -2,19 +2,49 @@ package log
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/aws/aws-xray-sdk-go/header"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 )
 
+// logMu guards the package logger state below, which the package level functions may change concurrently.
+var logMu sync.RWMutex
+
+// log is baseLog with the fields of the current invocation, see requestLog.
 var log *zap.SugaredLogger
+
+// baseLog is the logger configured by Init along with the fields added through With and WithCustomAttr.
+var baseLog *zap.SugaredLogger
+
+// traceLogFields and lambdaLogFields are the fields baseLog is extended with into log, set by SetupTraceIds
+// and SetupLambdaContext, which replace them on every invocation.
+var traceLogFields, lambdaLogFields []interface{}
+
+// std is the Instance the package functions delegate to, rebuilt by defaultInstance once the package logger changes.
+var std *Instance
+
+// initLog is the logger as configured by Init.
+var initLog *zap.SugaredLogger
+
+var defaultLogOnce sync.Once
+
+// level is the level of the package logger, adjustable at runtime through SetLevel.
+var level zap.AtomicLevel
 var logConfig Configuration
+var customAttrKeys map[string]bool
 
 type Configuration struct {
 	logLevel               string
@@ -23,8 +53,34 @@ type Configuration struct {
 	projectGroup           string
 	version                string
 	customAttributesPrefix string
+	moduleField            bool
+	devMode                bool
+	routingField           string
+	routes                 map[string]zapcore.Core
+	maxCustomAttributes    int
+	sequence               bool
+	marshalFailureMode     MarshalFailureMode
+	format                 Format
+	gcpProjectID           string
+	outputPaths            []string
+	errorOutputPaths       []string
+	cores                  []zapcore.Core
+	encoding               string
+	callerSkip             int
+	sampling               *zap.SamplingConfig
+	samplingDisabled       bool
+	levelSampling          map[zapcore.Level]*zap.SamplingConfig
+	otlpExporter           LogExporter
+	baggageKeys            map[string]bool
+	serviceName            string
+	baseFields             []zap.Field
+	xrayDisabled           bool
+	escapeControlChars     bool
+	timeEncoding           TimeEncoding
+	localTime              bool
 }
 
+// NewConfiguration takes the fields positionally, BuildConfiguration takes them as options and reports missing ones.
 func NewConfiguration(logLevel, application, project, projectGroup, version, customAttributesPrefix string) Configuration {
 	v := lambdacontext.FunctionVersion
 	if version != "" {
@@ -40,138 +96,779 @@ func NewConfiguration(logLevel, application, project, projectGroup, version, cus
 	}
 }
 
+// WithModuleField adds the Module field holding the import path of the package which emitted the entry.
+func (c Configuration) WithModuleField(enabled bool) Configuration {
+	c.moduleField = enabled
+	return c
+}
+
+// WithDevMode makes SetupTraceIds echo the trace id to stdout, ready to be pasted into a tracing UI,
+// and DPanic panic. Meant for local development only.
+func (c Configuration) WithDevMode(enabled bool) Configuration {
+	c.devMode = enabled
+	return c
+}
+
+// WithRouting sends entries whose field equals one of the routes keys to the matching core instead of stderr.
+func (c Configuration) WithRouting(field string, routes map[string]zapcore.Core) Configuration {
+	c.routingField = field
+	c.routes = routes
+	return c
+}
+
+// WithMaxCustomAttributes limits the number of distinct keys WithCustomAttr attaches to the logger, 0 means no limit.
+// Attributes past the limit are dropped with a warning.
+func (c Configuration) WithMaxCustomAttributes(max int) Configuration {
+	c.maxCustomAttributes = max
+	return c
+}
+
+// WithSequence adds the Seq field, see the Sequence key, a number incremented by one with every entry the process
+// writes to its outputs, so dropped lines show up as gaps. The entries of the sinks and of the WithCores cores
+// aren't numbered.
+func (c Configuration) WithSequence(enabled bool) Configuration {
+	c.sequence = enabled
+	return c
+}
+
+// WithEscapedControlCharacters escapes newlines and the other control characters of the messages and of the string
+// fields, those added through With and ContextWith included, e.g. as \n, so user input can't forge log lines.
+func (c Configuration) WithEscapedControlCharacters(enabled bool) Configuration {
+	c.escapeControlChars = enabled
+	return c
+}
+
+// WithMarshalFailureMode chooses how fields which can't be marshalled are logged, see MarshalFailureMode.
+func (c Configuration) WithMarshalFailureMode(mode MarshalFailureMode) Configuration {
+	c.marshalFailureMode = mode
+	return c
+}
+
+// WithFormat chooses the field layout of the emitted entries, see Format.
+func (c Configuration) WithFormat(format Format) Configuration {
+	c.format = format
+	return c
+}
+
+// WithGCPProjectID sets the project the FormatGCP trace resource path points to,
+// GOOGLE_CLOUD_PROJECT is used when it's not set.
+func (c Configuration) WithGCPProjectID(projectID string) Configuration {
+	c.gcpProjectID = projectID
+	return c
+}
+
+// WithOutputPaths replaces stderr with paths as the destinations of the entries, Stdout, Stderr or file paths,
+// see zap.Config.OutputPaths.
+func (c Configuration) WithOutputPaths(paths ...string) Configuration {
+	c.outputPaths = paths
+	return c
+}
+
+// WithErrorOutputPaths replaces stderr with paths as the destinations of the errors of the logger itself,
+// see zap.Config.ErrorOutputPaths.
+func (c Configuration) WithErrorOutputPaths(paths ...string) Configuration {
+	c.errorOutputPaths = paths
+	return c
+}
+
+// WithCores tees the entries into cores as well as into the output paths, e.g. a Firehose writer.
+// The entries carry the resource fields and pass the redaction, cores decide on their own which levels they take,
+// as with AddSink. Calls accumulate.
+func (c Configuration) WithCores(cores ...zapcore.Core) Configuration {
+	c.cores = append(c.cores[:len(c.cores):len(c.cores)], cores...)
+	return c
+}
+
+// WithEncoding chooses between the EncodingJSON default and EncodingConsole, readable when running functions locally.
+// Without it the encoding is taken from GOFRLIB_LOG_FORMAT, ignored inside Lambda so deployed functions keep JSON.
+func (c Configuration) WithEncoding(encoding string) Configuration {
+	c.encoding = encoding
+	return c
+}
+
+// resolveEncoding picks the encoding set through WithEncoding or, outside Lambda, GOFRLIB_LOG_FORMAT,
+// EncodingJSON otherwise.
+func (c Configuration) resolveEncoding() string {
+	if c.encoding != "" {
+		return c.encoding
+	}
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") == "" {
+		if encoding := strings.ToLower(strings.TrimSpace(os.Getenv(EncodingEnv))); encoding != "" {
+			return encoding
+		}
+	}
+	return EncodingJSON
+}
+
+// WithXRay(false) skips configuring the X-Ray SDK in Init, for functions traced with OpenTelemetry only.
+// SetupTraceIds and the trace fields then come from the span context alone, ignoring the X-Ray trace header.
+func (c Configuration) WithXRay(enabled bool) Configuration {
+	c.xrayDisabled = !enabled
+	return c
+}
+
+// WithServiceName sets Resource.service.name, taking precedence over OTEL_SERVICE_NAME and the default
+// <projectGroup>-<project>-<application>.
+func (c Configuration) WithServiceName(serviceName string) Configuration {
+	c.serviceName = serviceName
+	return c
+}
+
+// WithBaseFields adds fields, e.g. the region or the commit of the deployment, to every entry of the logger Init
+// configures, next to the resource fields. Calls add up.
+func (c Configuration) WithBaseFields(fields ...zap.Field) Configuration {
+	c.baseFields = append(c.baseFields[:len(c.baseFields):len(c.baseFields)], fields...)
+	return c
+}
+
+// WithCallerSkip sets the number of frames skipped to report the caller in the Resource.logger field, 1 by default
+// to skip the package function. A package wrapping this one sets 2 to report the callers of its own functions.
+func (c Configuration) WithCallerSkip(skip int) Configuration {
+	c.callerSkip = skip
+	return c
+}
+
+// WithSampling keeps the first initial entries with the same level and message every second and then every
+// thereafter-th one, 100 and 100 by default.
+func (c Configuration) WithSampling(initial, thereafter int) Configuration {
+	c.sampling = &zap.SamplingConfig{Initial: initial, Thereafter: thereafter}
+	c.samplingDisabled = false
+	return c
+}
+
+// WithoutSampling writes every entry, repeated ones included, until WithLevelSampling samples a level again.
+func (c Configuration) WithoutSampling() Configuration {
+	c.sampling = nil
+	c.samplingDisabled = true
+	c.levelSampling = nil
+	return c
+}
+
+// WithLevelSampling samples the entries of level with initial and thereafter, see WithSampling, in place of
+// the sampling of the other levels.
+func (c Configuration) WithLevelSampling(level zapcore.Level, initial, thereafter int) Configuration {
+	return c.withLevelSampling(level, &zap.SamplingConfig{Initial: initial, Thereafter: thereafter})
+}
+
+// WithoutLevelSampling writes every entry of level, e.g. ERROR for auditing, however the other levels are sampled.
+func (c Configuration) WithoutLevelSampling(level zapcore.Level) Configuration {
+	return c.withLevelSampling(level, nil)
+}
+
+func (c Configuration) withLevelSampling(level zapcore.Level, sampling *zap.SamplingConfig) Configuration {
+	levelSampling := make(map[zapcore.Level]*zap.SamplingConfig, len(c.levelSampling)+1)
+	for l, s := range c.levelSampling {
+		levelSampling[l] = s
+	}
+	levelSampling[level] = sampling
+	c.levelSampling = levelSampling
+	return c
+}
+
 // Customizes logger to unify log format with ec2 application loggers
 func Init(config Configuration) {
+	if err := InitE(config); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+}
+
+// InitE is Init returning the configuration problems Init only prints to stderr, so a Lambda can fail at init
+// instead of logging with fallbacks. A malformed log level falls back to INFO. An unknown format falls back to
+// the default one, an unknown time encoding to the default of the encoding and an unknown marshal failure mode
+// to MarshalFailureDefault. Negative sampling falls back to the default sampling, negative level sampling to that
+// of the other levels, a negative caller skip to 1 and a negative custom attributes limit to no limit.
+// Nil cores and route cores are left out. A logger which can't be built, e.g. for an unknown encoding or
+// an output which can't be opened, leaves the previous logger in place or, on the first call, installs one
+// writing to stderr.
+func InitE(config Configuration) error {
+	configured, logLevel, errs := build(config)
+	if configured == nil {
+		logMu.RLock()
+		installed := log != nil
+		logMu.RUnlock()
+		if installed {
+			return errs
+		}
+		config.outputPaths, config.errorOutputPaths = nil, nil
+		config.encoding = config.resolveEncoding()
+		if config.encoding != EncodingConsole {
+			config.encoding = EncodingJSON
+		}
+		rawLogger, _ := buildLogger(config, logLevel)
+		configured = configureLogger(rawLogger, config)
+	}
+
+	defer configured.Sync()
+
+	logMu.Lock()
+	previous := initLog
 	logConfig = config
-	var logLevel zap.AtomicLevel
-	if err := logLevel.UnmarshalText([]byte(config.logLevel)); err != nil {
-		fmt.Printf("malformed log level: %+v\n", config.logLevel)
-		logLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+	customAttrKeys = map[string]bool{}
+	level = logLevel
+	log = configured
+	initLog = configured
+	baseLog = configured
+	std = nil
+	traceLogFields, lambdaLogFields = nil, nil
+	logMu.Unlock()
+
+	// the replaced logger may still buffer entries, e.g. the records of its OTLP export
+	if previous != nil {
+		_ = previous.Sync()
+	}
+	if !config.xrayDisabled {
+		xraySetup(configured)
+	}
+	return errs
+}
+
+// validateOutputPaths rejects the empty output paths and the file paths whose directory doesn't exist,
+// which zap would only report as a failure to open them.
+func validateOutputPaths(config Configuration) error {
+	var errs error
+	for _, path := range append(append([]string{}, config.outputPaths...), config.errorOutputPaths...) {
+		switch {
+		case path == Stdout || path == Stderr || strings.Contains(path, "://"):
+		case strings.TrimSpace(path) == "":
+			errs = multierr.Append(errs, fmt.Errorf("empty output path"))
+		default:
+			if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+				errs = multierr.Append(errs, fmt.Errorf("output path %q: no such directory", path))
+			}
+		}
 	}
+	return errs
+}
+
+// resolveServiceName picks the Resource.service.name, set through WithServiceName, OTEL_SERVICE_NAME
+// or composed of the project group, project and application, in that order.
+func (c Configuration) resolveServiceName() string {
+	if c.serviceName != "" {
+		return c.serviceName
+	}
+	if serviceName := os.Getenv("OTEL_SERVICE_NAME"); serviceName != "" {
+		return serviceName
+	}
+	return fmt.Sprintf("%s-%s-%s", c.projectGroup, c.project, c.application)
+}
 
-	rawLogger, _ := zap.Config{
-		Level:       logLevel,
-		Development: false,
-		Encoding:    "json",
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
-		EncoderConfig: zapcore.EncoderConfig{
-			TimeKey:        Timestamp,
-			LevelKey:       Level,
-			NameKey:        "logger",
-			CallerKey:      Logger,
-			MessageKey:     Message,
-			StacktraceKey:  StackTrace,
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeLevel:    zapcore.CapitalLevelEncoder,
-			EncodeTime:     zapcore.ISO8601TimeEncoder,
-			EncodeDuration: zapcore.SecondsDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
-		},
-		ErrorOutputPaths: []string{"stderr"},
-		OutputPaths:      []string{"stderr"},
-	}.Build()
-
-	defer rawLogger.Sync()
-
-	serviceName := os.Getenv("OTEL_SERVICE_NAME")
-	if len(serviceName) == 0 {
-		// check env etc
-		serviceName = fmt.Sprintf("%s-%s-%s", config.projectGroup, config.project, config.application)
-	}
-	log = rawLogger.
-		WithOptions(zap.AddCallerSkip(1)).
+// configureLogger adds the caller skip, the resource fields and the base fields of config to rawLogger.
+func configureLogger(rawLogger *zap.Logger, config Configuration) *zap.SugaredLogger {
+	serviceName := config.resolveServiceName()
+	callerSkip := config.callerSkip
+	if callerSkip < 1 {
+		callerSkip = 1
+	}
+	return rawLogger.
+		WithOptions(zap.AddCallerSkip(callerSkip)).
 		With(zap.String(Application, config.application)).
 		With(zap.String(Project, config.project)).
 		With(zap.String(ProjectGroup, config.projectGroup)).
 		With(zap.String(ResourceServiceName, serviceName)).
 		With(zap.String(ResourceServiceVersion, config.version)).
 		With(zap.String(Version, config.version)).
+		With(zap.String(SchemaVersion, LogSchemaVersion)).
+		With(config.baseFields...).
 		Sugar()
+}
 
-	setUpXRay()
+func parseLevel(level string) (zap.AtomicLevel, error) {
+	l, err := levelOf(level)
+	return zap.NewAtomicLevelAt(l), err
 }
 
+// levelOf parses level case-insensitively, taking an empty level for INFO and WARNING for WARN.
+func levelOf(level string) (zapcore.Level, error) {
+	level = strings.TrimSpace(level)
+	if strings.EqualFold(level, "WARNING") {
+		level = "WARN"
+	}
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return zapcore.InfoLevel, err
+	}
+	return l, nil
+}
+
+// outputPathsOf returns the output and error output paths of config, stderr unless set.
+func outputPathsOf(config Configuration) (outputPaths, errorOutputPaths []string) {
+	outputPaths = config.outputPaths
+	if len(outputPaths) == 0 {
+		outputPaths = []string{Stderr}
+	}
+	errorOutputPaths = config.errorOutputPaths
+	if len(errorOutputPaths) == 0 {
+		errorOutputPaths = []string{Stderr}
+	}
+	return outputPaths, errorOutputPaths
+}
+
+func zapConfig(config Configuration, logLevel zap.AtomicLevel) zap.Config {
+	outputPaths, errorOutputPaths := outputPathsOf(config)
+	return zap.Config{
+		Level:            logLevel,
+		Development:      false,
+		Encoding:         config.resolveEncoding(),
+		EncoderConfig:    encoderConfig(config),
+		ErrorOutputPaths: errorOutputPaths,
+		OutputPaths:      outputPaths,
+	}
+}
+
+// SetupTraceIds replaces the trace fields of the package logger with those of ctx. A Lambda execution environment
+// is reused across invocations, so the fields of the previous invocation are dropped rather than stacked.
+// Prefer LoggerFromContext to leave the package logger untouched.
 func SetupTraceIds(ctx context.Context) context.Context {
+	if traceId, _, _, ok := traceIdentity(ctx); ok {
+		logger()
+		fields := traceFields(ctx)
+		logMu.Lock()
+		traceLogFields = fields
+		log = requestLog()
+		std = nil
+		devMode := logConfig.devMode
+		logMu.Unlock()
+		if devMode {
+			fmt.Fprintf(os.Stdout, "trace: %s\n", traceId)
+		}
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); !spanContext.IsValid() {
+		if traceHeader := getTraceHeaderFromContext(ctx); traceHeader != nil {
+			tId, err := trace.TraceIDFromHex(ToW3C(traceHeader.TraceID))
+			if err == nil {
+				return trace.ContextWithSpanContext(ctx, trace.SpanContext{}.
+					WithTraceID(tId))
+			}
+		}
+	}
+	return ctx
+}
+
+// traceFields returns the trace correlation fields of ctx, taken from the span context or, failing that, the X-Ray header.
+func traceFields(ctx context.Context) []interface{} {
+	traceId, spanId, sampled, ok := traceIdentity(ctx)
+	if !ok {
+		return nil
+	}
+	logMu.RLock()
+	format, gcpProjectID := logConfig.format, logConfig.gcpProjectID
+	logMu.RUnlock()
+	if format == FormatGCP {
+		return gcpTraceFields(gcpProjectID, traceId, spanId, sampled)
+	}
+	return []interface{}{
+		TraceId, traceId,
+		CorrelationId, traceId,
+		SpanId, spanId,
+		TraceFlags, sampled,
+	}
+}
+
+func traceIdentity(ctx context.Context) (traceId, spanId string, sampled, ok bool) {
 	spanContext := trace.SpanContextFromContext(ctx)
 	if spanContext.IsValid() {
-		log = log.
-			With(TraceId, spanContext.TraceID().String()).
-			With(CorrelationId, spanContext.TraceID().String()).
-			With(SpanId, spanContext.SpanID().String()).
-			With(TraceFlags, spanContext.TraceFlags().IsSampled())
+		return spanContext.TraceID().String(), spanContext.SpanID().String(), spanContext.TraceFlags().IsSampled(), true
 	} else if traceHeader := getTraceHeaderFromContext(ctx); traceHeader != nil {
-		traceId := ToW3C(traceHeader.TraceID)
-		log = log.
-			With(TraceId, traceId).
-			With(CorrelationId, traceId).
-			With(SpanId, traceHeader.ParentID).
-			With(TraceFlags, traceHeader.SamplingDecision == header.Sampled)
-		tId, err := trace.TraceIDFromHex(traceId)
-		if err == nil {
-			return trace.ContextWithSpanContext(ctx, trace.SpanContext{}.
-				WithTraceID(tId))
+		return ToW3C(traceHeader.TraceID), traceHeader.ParentID, traceHeader.SamplingDecision == header.Sampled, true
+	}
+	return "", "", false, false
+}
+
+// logger returns the package logger, installing a default one logging INFO entries to stderr
+// when a logging function runs before Init.
+func logger() *zap.SugaredLogger {
+	defaultLogOnce.Do(func() {
+		if log == nil {
+			Init(NewConfiguration("INFO", "", "", "", "", ""))
 		}
+	})
+	logMu.RLock()
+	defer logMu.RUnlock()
+	return log
+}
+
+// defaultInstance returns the Instance of the package logger. Its loggers skip one more frame than those of New,
+// the one of the package function delegating to it.
+func defaultInstance() *Instance {
+	logger()
+	logMu.RLock()
+	instance := std
+	logMu.RUnlock()
+	if instance != nil {
+		return instance
 	}
-	return ctx
+
+	logMu.Lock()
+	defer logMu.Unlock()
+	if std == nil {
+		std = &Instance{config: logConfig, level: level, log: log.WithOptions(zap.AddCallerSkip(1)), packageLogger: true}
+	}
+	return std
+}
+
+// ZapLogger returns the package logger for libraries integrating their logging through a *zap.Logger, so their entries
+// carry the resource fields too. Init replaces the package logger, so don't keep the result across Init calls.
+// It isn't named Logger, which is taken by the caller field key.
+func ZapLogger() *zap.Logger {
+	return SugaredLogger().Desugar()
+}
+
+// SugaredLogger is ZapLogger returning the *zap.SugaredLogger. The same caching caveat applies.
+func SugaredLogger() *zap.SugaredLogger {
+	// the package functions skip their own frame, the callers of the returned logger have none to skip
+	return logger().WithOptions(zap.AddCallerSkip(-1))
+}
+
+// baseLogger returns the logger the package logger was derived from by SetupTraceIds.
+func baseLogger() *zap.SugaredLogger {
+	logger()
+	logMu.RLock()
+	defer logMu.RUnlock()
+	return baseLog
+}
+
+// SetLevel changes the level of the package logger, and of the loggers derived from it, at runtime.
+func SetLevel(logLevel string) error {
+	return defaultInstance().SetLevel(logLevel)
+}
+
+// GetLevel returns the current level of the package logger, e.g. "INFO".
+func GetLevel() string {
+	return currentLevel().Level().CapitalString()
+}
+
+func currentLevel() zap.AtomicLevel {
+	logger()
+	logMu.RLock()
+	defer logMu.RUnlock()
+	return level
 }
 
 func Flush() error {
-	return log.Sync()
+	return defaultInstance().Flush()
+}
+
+// FlushCtx is Flush giving up when ctx is done, so a sink which blocks on Sync can't hang the end of an invocation.
+// It ignores the EINVAL and ENOTTY errors returned by syncing stderr attached to a pipe or a terminal.
+func FlushCtx(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- Flush()
+	}()
+	select {
+	case err := <-done:
+		return withoutBenignSyncErrors(err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func withoutBenignSyncErrors(err error) error {
+	var errs error
+	for _, err := range multierr.Errors(err) {
+		if !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTTY) {
+			errs = multierr.Append(errs, err)
+		}
+	}
+	return errs
 }
 
 func Debug(template string, args ...interface{}) {
-	log.Debugf(template, args...)
+	defaultInstance().Debug(template, args...)
 }
 
 func DebugW(msg string, keysAndValues ...interface{}) {
-	log.Debugw(msg, keysAndValues...)
+	defaultInstance().DebugW(msg, keysAndValues...)
 }
 
 func Info(template string, args ...interface{}) {
-	log.Infof(template, args...)
+	defaultInstance().Info(template, args...)
 }
 
 func InfoW(msg string, keysAndValues ...interface{}) {
-	log.Infow(msg, keysAndValues...)
+	defaultInstance().InfoW(msg, keysAndValues...)
 }
 
 func Warn(template string, args ...interface{}) {
-	log.Warnf(template, args...)
+	defaultInstance().Warn(template, args...)
 }
 
 func WarnW(msg string, keysAndValues ...interface{}) {
-	log.Warnw(msg, keysAndValues...)
+	defaultInstance().WarnW(msg, keysAndValues...)
 }
 
 func Error(template string, args ...interface{}) {
-	log.Errorf(template, args...)
+	defaultInstance().Error(template, args...)
 }
 
 func ErrorW(msg string, keysAndValues ...interface{}) {
-	log.Errorw(msg, keysAndValues...)
+	defaultInstance().ErrorW(msg, keysAndValues...)
+}
+
+// The ...Fields functions take typed fields, skipping the interface{} handling of the ...W functions
+// in the hot paths.
+
+func DebugFields(msg string, fields ...zap.Field) {
+	defaultInstance().DebugFields(msg, fields...)
+}
+
+func InfoFields(msg string, fields ...zap.Field) {
+	defaultInstance().InfoFields(msg, fields...)
+}
+
+func WarnFields(msg string, fields ...zap.Field) {
+	defaultInstance().WarnFields(msg, fields...)
+}
+
+func ErrorFields(msg string, fields ...zap.Field) {
+	defaultInstance().ErrorFields(msg, fields...)
+}
+
+// DebugLazy is DebugW building the fields with fn only when debug is enabled, so expensive fields
+// such as ToString of a payload cost nothing otherwise.
+func DebugLazy(msg string, fn func() []interface{}) {
+	defaultInstance().DebugLazy(msg, fn)
+}
+
+// InfoLazy is DebugLazy at info level.
+func InfoLazy(msg string, fn func() []interface{}) {
+	defaultInstance().InfoLazy(msg, fn)
+}
+
+// Fatal logs at fatal level and exits the process with status 1, after flushing the logger.
+func Fatal(template string, args ...interface{}) {
+	defaultInstance().Fatal(template, args...)
+}
+
+// FatalW is Fatal with structured fields.
+func FatalW(msg string, keysAndValues ...interface{}) {
+	defaultInstance().FatalW(msg, keysAndValues...)
+}
+
+// Panic logs at panic level and then panics with the message, after flushing the logger.
+func Panic(template string, args ...interface{}) {
+	defaultInstance().Panic(template, args...)
+}
+
+// PanicW is Panic with structured fields.
+func PanicW(msg string, keysAndValues ...interface{}) {
+	defaultInstance().PanicW(msg, keysAndValues...)
+}
+
+// DPanic logs at dpanic level and, in dev mode, then panics with the message, after flushing the logger.
+func DPanic(template string, args ...interface{}) {
+	defaultInstance().DPanic(template, args...)
+}
+
+// DPanicW is DPanic with structured fields.
+func DPanicW(msg string, keysAndValues ...interface{}) {
+	defaultInstance().DPanicW(msg, keysAndValues...)
+}
+
+// buildOptions are the options Init and New build the logger with, exit being its fatal hook.
+func buildOptions(config Configuration, exit *flushThenExit) []zap.Option {
+	options := []zap.Option{wrapCore(config), zap.WithFatalHook(exit)}
+	if config.devMode {
+		options = append(options, zap.Development())
+	}
+	return options
+}
+
+// flushThenExit replaces the zap fatal hook, so entries buffered by the wrapped cores aren't lost on exit.
+type flushThenExit struct {
+	logger *zap.Logger
+}
+
+func (f *flushThenExit) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	if f.logger != nil {
+		_ = f.logger.Sync()
+	}
+	os.Exit(1)
+}
+
+// buildLogger builds the logger of config at logLevel, flushing itself on fatal entries.
+func buildLogger(config Configuration, logLevel zap.AtomicLevel) (*zap.Logger, error) {
+	if err := validateOutputPaths(config); err != nil {
+		return nil, err
+	}
+	exit := &flushThenExit{}
+	rawLogger, err := zapConfig(config, logLevel).Build(buildOptions(config, exit)...)
+	if err != nil {
+		return nil, err
+	}
+	exit.logger = rawLogger
+	return rawLogger, nil
 }
 
+// build is the part of Init and New building the logger of config, along with the problems of config. A malformed
+// level falls back to INFO, the logger is nil when it can't be built.
+func build(config Configuration) (*zap.SugaredLogger, zap.AtomicLevel, error) {
+	var errs error
+	logLevel, err := parseLevel(config.logLevel)
+	if err != nil {
+		errs = malformedLevelError(config.logLevel)
+		logLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+	}
+	errs = multierr.Append(errs, checkConfiguration(config))
+	rawLogger, err := buildLogger(config, logLevel)
+	if err != nil {
+		return nil, logLevel, multierr.Append(errs, fmt.Errorf("building logger: %w", err))
+	}
+	return configureLogger(rawLogger, config), logLevel, errs
+}
+
+func malformedLevelError(logLevel string) error {
+	return fmt.Errorf("malformed log level: %+v", logLevel)
+}
+
+// With adds args to every entry the package logger writes from now on, across invocations and goroutines.
+// Prefer ContextWith for the fields of a single request.
 func With(args ...interface{}) {
+	logger()
+	logMu.Lock()
+	defer logMu.Unlock()
+	baseLog = baseLog.With(args...)
 	log = log.With(args...)
+	std = nil
+}
+
+// ResetRequestFields drops the trace fields and the fields added through With and WithCustomAttr,
+// so a handler can start every invocation from the logger configured by Init.
+func ResetRequestFields() {
+	logger()
+	logMu.Lock()
+	defer logMu.Unlock()
+	log = initLog
+	baseLog = initLog
+	std = nil
+	traceLogFields, lambdaLogFields = nil, nil
+	customAttrKeys = map[string]bool{}
+}
+
+// requestLog extends baseLog with the fields of the current invocation, logMu has to be held.
+func requestLog() *zap.SugaredLogger {
+	fields := append(append([]interface{}{}, traceLogFields...), lambdaLogFields...)
+	if len(fields) == 0 {
+		return baseLog
+	}
+	return baseLog.With(fields...)
 }
 
+// WithCustomAttr attaches key to the package logger as a Body.<customAttributesPrefix>.<key> field,
+// warning and dropping the attributes WithCustomAttrE rejects. Prefer ContextWithCustomAttr for the attributes
+// of a single request.
 func WithCustomAttr(key string, value interface{}) {
-	log = log.With(fmt.Sprintf("Body.%s.%s", logConfig.customAttributesPrefix, key), value)
+	if err := WithCustomAttrE(key, value); err != nil {
+		logger().Warnf("custom attribute %s dropped, %v", key, err)
+	}
+}
+
+// WithCustomAttrE is WithCustomAttr returning an error instead of attaching an empty key, a key colliding with
+// one of ReservedKeys or a key past the limit set through WithMaxCustomAttributes.
+func WithCustomAttrE(key string, value interface{}) error {
+	logger()
+	logMu.Lock()
+	defer logMu.Unlock()
+	attr, err := checkCustomAttr(key)
+	if err != nil {
+		return err
+	}
+	customAttrKeys[key] = true
+	baseLog = baseLog.With(attr, value)
+	log = requestLog()
+	std = nil
+	return nil
+}
+
+// WithCustomAttrs attaches all of attrs at once, as WithCustomAttr would one by one, but rebuilding the package
+// logger a single time.
+func WithCustomAttrs(attrs map[string]interface{}) {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	logger()
+	logMu.Lock()
+	var fields []interface{}
+	var dropped []string
+	for _, key := range keys {
+		attr, err := checkCustomAttr(key)
+		if err != nil {
+			dropped = append(dropped, fmt.Sprintf("custom attribute %s dropped, %v", key, err))
+			continue
+		}
+		customAttrKeys[key] = true
+		fields = append(fields, attr, attrs[key])
+	}
+	if len(fields) > 0 {
+		baseLog = baseLog.With(fields...)
+		log = requestLog()
+		std = nil
+	}
+	current := log
+	logMu.Unlock()
+
+	for _, warning := range dropped {
+		current.Warn(warning)
+	}
+}
+
+// checkCustomAttr returns the field key of the custom attribute key or why it can't be attached, logMu has to be held.
+func checkCustomAttr(key string) (string, error) {
+	attr, err := customAttrField(logConfig.customAttributesPrefix, key)
+	if err != nil {
+		return "", err
+	}
+	if limit := logConfig.maxCustomAttributes; limit > 0 && !customAttrKeys[key] && len(customAttrKeys) >= limit {
+		return "", fmt.Errorf("limit of %d custom attributes reached", limit)
+	}
+	return attr, nil
+}
+
+// customAttrField returns the field key of the custom attribute key, rejecting an empty key and one colliding
+// with one of ReservedKeys.
+func customAttrField(prefix, key string) (string, error) {
+	if key == "" {
+		return "", errors.New("empty key")
+	}
+	attr := customAttrKey(prefix, key)
+	for _, reserved := range ReservedKeys() {
+		if attr == reserved {
+			return "", fmt.Errorf("%s collides with a reserved key", attr)
+		}
+	}
+	return attr, nil
+}
+
+// customAttrKey composes the field key of a custom attribute, leaving out an empty prefix.
+func customAttrKey(prefix, key string) string {
+	if prefix == "" {
+		return "Body." + key
+	}
+	return fmt.Sprintf("Body.%s.%s", prefix, key)
+}
+
+// IsLevelEnabled reports whether entries of level are logged, to skip gathering what only such entries would carry.
+func IsLevelEnabled(level zapcore.Level) bool {
+	return defaultInstance().IsLevelEnabled(level)
 }
 
 func IsDebugEnabled() bool {
-	return log.Desugar().Check(zapcore.DebugLevel, "") != nil
+	return defaultInstance().IsDebugEnabled()
 }
 
 func IsInfoEnabled() bool {
-	return log.Desugar().Check(zapcore.InfoLevel, "") != nil
+	return defaultInstance().IsInfoEnabled()
 }
 
 func IsWarnEnabled() bool {
-	return log.Desugar().Check(zapcore.WarnLevel, "") != nil
+	return defaultInstance().IsWarnEnabled()
+}
+
+func IsErrorEnabled() bool {
+	return defaultInstance().IsErrorEnabled()
 }
 
 func ToString(value interface{}) string {
@@ -182,16 +879,37 @@ func ToString(value interface{}) string {
 	return string(bytes)
 }
 
+// ToW3C converts an X-Ray trace id into a W3C one, returning a malformed id unchanged, see ToW3CWithError.
 func ToW3C(xrayTraceID string) string {
+	traceId, err := ToW3CWithError(xrayTraceID)
+	if err != nil {
+		return xrayTraceID
+	}
+	return traceId
+}
+
+// ToW3CWithError converts an X-Ray trace id, e.g. 1-5759e988-bd862e3fe1be46a994272793, into a W3C one
+// by joining its 8 hex digits epoch and 24 hex digits unique parts.
+func ToW3CWithError(xrayTraceID string) (string, error) {
 	// Split the X-Ray trace ID into parts
 	parts := strings.Split(xrayTraceID, "-")
 
 	// Check if the X-Ray trace ID has the expected number of parts
 	if len(parts) != 3 {
-		log.Error("invalid X-Ray trace ID format")
-		return xrayTraceID
+		return "", fmt.Errorf("invalid X-Ray trace ID format: %q", xrayTraceID)
+	}
+	if !isHex(parts[1], 8) || !isHex(parts[2], 24) {
+		return "", fmt.Errorf("invalid X-Ray trace ID hex parts: %q", xrayTraceID)
 	}
 
 	// Extract the relevant parts for the OpenTelemetry trace ID
-	return parts[1] + parts[2]
+	return parts[1] + parts[2], nil
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
 }
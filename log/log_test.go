@@ -5,6 +5,11 @@ import (
 	"github.com/Ryanair/gofrlib/log"
 	"github.com/aws/aws-xray-sdk-go/xray"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"os"
 	"testing"
 )
 
@@ -104,3 +109,191 @@ func TestLogTraceIds(t *testing.T) {
 	log.SetupTraceIds(ctx)
 	log.Debug("Debug msg with value in context")
 }
+
+func TestLogSchemaVersion(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("DEBUG"), func() {
+		log.Debug("Debug msg")
+		log.InfoW("InfoW msg", "test-key", "test-value")
+		log.Error("Error msg")
+	})
+	// THEN
+	assert.Len(t, entries, 3)
+	for _, entry := range entries {
+		assert.Equal(t, log.LogSchemaVersion, entry[log.SchemaVersion])
+	}
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	require.NoError(t, err)
+	defer file.Close()
+
+	stdout := os.Stdout
+	os.Stdout = file
+	fn()
+	os.Stdout = stdout
+
+	content, err := os.ReadFile(file.Name())
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestSetupTraceIdsEchoesTraceInDevMode(t *testing.T) {
+	// GIVEN
+	traceId, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanId, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId,
+		SpanID:  spanId,
+	}))
+
+	for name, devMode := range map[string]bool{"dev": true, "default": false} {
+		t.Run(name, func(t *testing.T) {
+			// WHEN
+			var stdout string
+			captureOutput(t, testConfiguration("INFO").WithDevMode(devMode), func() {
+				stdout = captureStdout(t, func() {
+					log.SetupTraceIds(ctx)
+				})
+			})
+			// THEN
+			if devMode {
+				assert.Equal(t, "trace: 0af7651916cd43dd8448eb211c80319c\n", stdout)
+			} else {
+				assert.Empty(t, stdout)
+			}
+		})
+	}
+}
+
+func TestWithCustomAttrDropsAttributesPastLimit(t *testing.T) {
+	// GIVEN
+	config := testConfiguration("INFO").WithMaxCustomAttributes(2)
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		log.WithCustomAttr("CustomAttrKey1", 1)
+		log.WithCustomAttr("CustomAttrKey2", 2)
+		log.WithCustomAttr("CustomAttrKey1", 11)
+		log.WithCustomAttr("CustomAttrKey3", 3)
+		log.Info("Info msg with custom attributes")
+	})
+	// THEN
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+	assert.Contains(t, entries[0][log.Message], "CustomAttrKey3")
+	assert.Contains(t, entries[1], "Body.testprefix.CustomAttrKey1")
+	assert.Contains(t, entries[1], "Body.testprefix.CustomAttrKey2")
+	assert.NotContains(t, entries[1], "Body.testprefix.CustomAttrKey3")
+}
+
+func TestWithCustomAttrE(t *testing.T) {
+	// WHEN
+	var err error
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		err = log.WithCustomAttrE("tenant", "FR")
+		log.Info("Info msg with custom attribute")
+	})
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "FR", entries[0]["Body.testprefix.tenant"])
+}
+
+func TestWithCustomAttrRejectsReservedKey(t *testing.T) {
+	// GIVEN
+	config := log.NewConfiguration("INFO", "test-application", "", "", "", "")
+	// WHEN
+	var err error
+	entries := captureOutput(t, config, func() {
+		err = log.WithCustomAttrE("message", "overwritten")
+		log.WithCustomAttr("stacktrace", "overwritten")
+		log.Info("Info msg")
+	})
+	// THEN
+	assert.ErrorContains(t, err, "Body.message collides with a reserved key")
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+	assert.Contains(t, entries[0][log.Message], "stacktrace")
+	assert.Equal(t, "Info msg", entries[1][log.Message])
+	assert.NotContains(t, entries[1], log.StackTrace)
+}
+
+func TestWithCustomAttrRejectsErrorKeys(t *testing.T) {
+	// GIVEN
+	config := log.NewConfiguration("INFO", "test-application", "", "", "", "")
+	// WHEN
+	var errs []error
+	captureOutput(t, config, func() {
+		for _, key := range []string{"error.message", "error.code", "error.stack"} {
+			errs = append(errs, log.WithCustomAttrE(key, "overwritten"))
+		}
+	})
+	// THEN
+	for _, err := range errs {
+		assert.ErrorContains(t, err, "collides with a reserved key")
+	}
+}
+
+func TestWithCustomAttrWithEmptyPrefix(t *testing.T) {
+	// GIVEN
+	config := log.NewConfiguration("INFO", "test-application", "", "", "", "")
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		log.WithCustomAttr("tenant", "FR")
+		log.Info("Info msg")
+	})
+	// THEN
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "FR", entries[0]["Body.tenant"])
+	assert.NotContains(t, entries[0], "Body..tenant")
+}
+
+// withCountingCore counts the With calls made on the observer core it wraps.
+type withCountingCore struct {
+	zapcore.Core
+	withs *int
+}
+
+func (c withCountingCore) With(fields []zapcore.Field) zapcore.Core {
+	*c.withs++
+	return withCountingCore{Core: c.Core.With(fields), withs: c.withs}
+}
+
+func (c withCountingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func TestWithCustomAttrs(t *testing.T) {
+	// GIVEN
+	log.Init(testConfiguration("INFO"))
+	core, logs := observer.New(zapcore.InfoLevel)
+	withs := 0
+	restore := log.SetTestCore(withCountingCore{Core: core, withs: &withs})
+	defer restore()
+	withsBefore := withs
+	// WHEN
+	log.WithCustomAttrs(map[string]interface{}{
+		"tenant":     "FR",
+		"market":     "IE",
+		"loyalty":    true,
+		"":           "dropped",
+	})
+	withsAfter := withs
+	log.Info("Info msg with custom attributes")
+	// THEN
+	assert.Equal(t, 1, withsAfter-withsBefore)
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Contains(t, entries[0].Message, "empty key")
+	fields := entries[1].ContextMap()
+	assert.Equal(t, "FR", fields["Body.testprefix.tenant"])
+	assert.Equal(t, "IE", fields["Body.testprefix.market"])
+	assert.Equal(t, true, fields["Body.testprefix.loyalty"])
+	assert.NotContains(t, fields, "Body.testprefix.")
+}
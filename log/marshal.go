@@ -0,0 +1,171 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// MarshalFailureMode decides what happens with a field the encoder fails to marshal.
+type MarshalFailureMode int
+
+const (
+	// MarshalFailureDefault keeps the zap behaviour of adding a <key>Error field describing the failure.
+	MarshalFailureDefault MarshalFailureMode = iota
+	// MarshalFailureOmit silently drops the field.
+	MarshalFailureOmit
+	// MarshalFailurePlaceholder replaces the value with MarshalFailurePlaceholderValue.
+	MarshalFailurePlaceholder
+	// MarshalFailureWarn drops the field and logs a warning naming it.
+	MarshalFailureWarn
+)
+
+const MarshalFailurePlaceholderValue = "<unmarshalable>"
+
+// marshalFailureCore handles fields which can't be marshalled before they reach the encoder, attached through With included.
+type marshalFailureCore struct {
+	zapcore.Core
+	mode MarshalFailureMode
+}
+
+func (c *marshalFailureCore) With(fields []zapcore.Field) zapcore.Core {
+	fields, failures := c.handle(fields)
+	c.warn(zapcore.Entry{Time: time.Now()}, failures)
+	return &marshalFailureCore{Core: c.Core.With(fields), mode: c.mode}
+}
+
+func (c *marshalFailureCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *marshalFailureCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	fields, failures := c.handle(fields)
+	if err := c.Core.Write(entry, fields); err != nil {
+		return err
+	}
+	c.warn(entry, failures)
+	return nil
+}
+
+func (c *marshalFailureCore) handle(fields []zapcore.Field) ([]zapcore.Field, []string) {
+	var handled []zapcore.Field
+	var failures []string
+	for i, field := range fields {
+		marshalled, err := marshal(field)
+		if err == nil && marshalled == nil {
+			if handled != nil {
+				handled = append(handled, field)
+			}
+			continue
+		}
+		if handled == nil {
+			handled = append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+		}
+		if err == nil {
+			handled = append(handled, marshalled...)
+			continue
+		}
+		switch c.mode {
+		case MarshalFailurePlaceholder:
+			handled = append(handled, zap.String(field.Key, MarshalFailurePlaceholderValue))
+		case MarshalFailureWarn:
+			failures = append(failures, fmt.Sprintf("%s: %v", field.Key, err))
+		}
+	}
+	if handled == nil {
+		return fields, nil
+	}
+	return handled, failures
+}
+
+func (c *marshalFailureCore) warn(entry zapcore.Entry, failures []string) {
+	for _, failure := range failures {
+		warning := zapcore.Entry{
+			Level:      zapcore.WarnLevel,
+			Time:       entry.Time,
+			LoggerName: entry.LoggerName,
+			Message:    "log field dropped, marshalling failed",
+			Caller:     entry.Caller,
+		}
+		if c.Enabled(warning.Level) {
+			_ = c.Core.Write(warning, []zapcore.Field{zap.String("Body.logging.failure", failure)})
+		}
+	}
+}
+
+// marshal reports the error the encoder would hit marshalling field. The marshallers run once: their output is
+// returned as the fields to log in place of field, nil when field is kept as it is.
+func marshal(field zapcore.Field) ([]zapcore.Field, error) {
+	switch field.Type {
+	case zapcore.ReflectType:
+		_, err := json.Marshal(field.Interface)
+		return nil, err
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.StringerType, zapcore.ErrorType:
+		encoder := zapcore.NewMapObjectEncoder()
+		field.AddTo(encoder)
+		if failure, failed := encoder.Fields[field.Key+"Error"]; failed {
+			return nil, fmt.Errorf("%v", failure)
+		}
+		marshalled := make([]zapcore.Field, 0, len(encoder.Fields))
+		for _, key := range sortedKeys(encoder.Fields) {
+			marshalled = append(marshalled, marshalledField(key, encoder.Fields[key]))
+		}
+		return marshalled, nil
+	}
+	return nil, nil
+}
+
+// marshalledField logs a value recorded by zapcore.MapObjectEncoder under key.
+func marshalledField(key string, value interface{}) zapcore.Field {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return zap.Object(key, marshalledObject(v))
+	case []interface{}:
+		return zap.Array(key, marshalledArray(v))
+	}
+	return zap.Any(key, value)
+}
+
+type marshalledObject map[string]interface{}
+
+func (o marshalledObject) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	for _, key := range sortedKeys(o) {
+		marshalledField(key, o[key]).AddTo(encoder)
+	}
+	return nil
+}
+
+type marshalledArray []interface{}
+
+func (a marshalledArray) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, item := range a {
+		switch v := item.(type) {
+		case map[string]interface{}:
+			_ = encoder.AppendObject(marshalledObject(v))
+		case []interface{}:
+			_ = encoder.AppendArray(marshalledArray(v))
+		case string:
+			encoder.AppendString(v)
+		case bool:
+			encoder.AppendBool(v)
+		case int:
+			encoder.AppendInt(v)
+		case int64:
+			encoder.AppendInt64(v)
+		case float64:
+			encoder.AppendFloat64(v)
+		case time.Time:
+			encoder.AppendTime(v)
+		case time.Duration:
+			encoder.AppendDuration(v)
+		default:
+			_ = encoder.AppendReflected(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+type countedBooking struct {
+	marshalled *int
+}
+
+func (b countedBooking) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	*b.marshalled++
+	encoder.AddString("id", "ABC123")
+	return encoder.AddArray("seats", zapcore.ArrayMarshalerFunc(func(encoder zapcore.ArrayEncoder) error {
+		encoder.AppendString("1A")
+		encoder.AppendInt(2)
+		return nil
+	}))
+}
+
+func TestMarshalFailureModes(t *testing.T) {
+	unmarshalable := map[string]interface{}{"callback": func() {}}
+
+	t.Run("default", func(t *testing.T) {
+		// WHEN
+		entries := captureOutput(t, testConfiguration("INFO"), func() {
+			log.InfoW("Info msg", "payload", unmarshalable, "test-key", "test-value")
+		})
+		// THEN
+		assert.Len(t, entries, 1)
+		assert.Contains(t, entries[0], "payloadError")
+		assert.Equal(t, "test-value", entries[0]["test-key"])
+	})
+
+	t.Run("omit", func(t *testing.T) {
+		// WHEN
+		entries := captureOutput(t, testConfiguration("INFO").WithMarshalFailureMode(log.MarshalFailureOmit), func() {
+			log.InfoW("Info msg", "payload", unmarshalable, "test-key", "test-value")
+		})
+		// THEN
+		assert.Len(t, entries, 1)
+		assert.NotContains(t, entries[0], "payload")
+		assert.NotContains(t, entries[0], "payloadError")
+		assert.Equal(t, "test-value", entries[0]["test-key"])
+	})
+
+	t.Run("placeholder", func(t *testing.T) {
+		// WHEN
+		entries := captureOutput(t, testConfiguration("INFO").WithMarshalFailureMode(log.MarshalFailurePlaceholder), func() {
+			log.With("context", unmarshalable)
+			log.InfoW("Info msg", "payload", unmarshalable)
+		})
+		// THEN
+		assert.Len(t, entries, 1)
+		assert.Equal(t, log.MarshalFailurePlaceholderValue, entries[0]["payload"])
+		assert.Equal(t, log.MarshalFailurePlaceholderValue, entries[0]["context"])
+		assert.NotContains(t, entries[0], "payloadError")
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		// WHEN
+		entries := captureOutput(t, testConfiguration("INFO").WithMarshalFailureMode(log.MarshalFailureWarn), func() {
+			log.InfoW("Info msg", "payload", unmarshalable)
+		})
+		// THEN
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "Info msg", entries[0][log.Message])
+		assert.NotContains(t, entries[0], "payload")
+		assert.Equal(t, "WARN", entries[1][log.Level])
+		assert.Contains(t, entries[1]["Body.logging.failure"], "payload")
+	})
+}
+
+func TestMarshalFailureModeMarshalsOnce(t *testing.T) {
+	// GIVEN
+	marshalled := 0
+
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithMarshalFailureMode(log.MarshalFailureOmit), func() {
+		log.InfoW("Info msg", "booking", countedBooking{marshalled: &marshalled})
+	})
+
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, 1, marshalled)
+	assert.Equal(t, map[string]interface{}{"id": "ABC123", "seats": []interface{}{"1A", float64(2)}}, entries[0]["booking"])
+}
@@ -0,0 +1,33 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"runtime"
+	"strings"
+)
+
+func moduleHook(entry zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+	if !entry.Caller.Defined {
+		return entry, fields
+	}
+	if module := callerModule(entry.Caller.PC); module != "" {
+		fields = append(fields, zap.String(Module, module))
+	}
+	return entry, fields
+}
+
+// callerModule returns the import path of the package the function at pc belongs to,
+// e.g. "github.com/Ryanair/gofrlib/log" for "github.com/Ryanair/gofrlib/log.(*xRayLogger).Log".
+func callerModule(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	lastSlash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[lastSlash+1:], "."); dot >= 0 {
+		return name[:lastSlash+1+dot]
+	}
+	return name
+}
@@ -0,0 +1,30 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestModuleField(t *testing.T) {
+	// GIVEN
+	config := testConfiguration("INFO").WithModuleField(true)
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		log.Info("Info msg with module")
+	})
+	// THEN
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "github.com/Ryanair/gofrlib/log_test", entries[0][log.Module])
+	assert.Contains(t, entries[0][log.Logger], "module_test.go")
+}
+
+func TestModuleFieldDisabledByDefault(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.Info("Info msg without module")
+	})
+	// THEN
+	assert.Len(t, entries, 1)
+	assert.NotContains(t, entries[0], log.Module)
+}
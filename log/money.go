@@ -0,0 +1,56 @@
+package log
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+)
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit isn't a hundredth.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+type money struct {
+	amount   int64
+	currency string
+}
+
+// Money returns a field logging amount, given in minor units of currency (e.g. cents), without going through floats.
+// The field holds the exact minor units, the currency and the amount formatted in major units, e.g. "123.45 EUR".
+func Money(key string, amount int64, currency string) zap.Field {
+	return zap.Object(key, money{amount: amount, currency: strings.ToUpper(currency)})
+}
+
+func (m money) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddInt64("minorUnits", m.amount)
+	encoder.AddString("currency", m.currency)
+	encoder.AddString("formatted", m.format())
+	return nil
+}
+
+func (m money) format() string {
+	exponent, found := currencyExponents[m.currency]
+	if !found {
+		exponent = 2
+	}
+
+	sign := ""
+	units := uint64(m.amount)
+	if m.amount < 0 {
+		sign = "-"
+		units = uint64(-(m.amount + 1)) + 1
+	}
+	if exponent == 0 {
+		return fmt.Sprintf("%s%d %s", sign, units, m.currency)
+	}
+
+	divisor := uint64(1)
+	for i := 0; i < exponent; i++ {
+		divisor *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, units/divisor, exponent, units%divisor, m.currency)
+}
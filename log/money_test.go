@@ -0,0 +1,27 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.InfoW("Payment captured",
+			log.Money("amount", 9007199254740993, "eur"),
+			log.Money("refund", -5, "EUR"),
+			log.Money("fee", 1500, "JPY"),
+			log.Money("tax", 1234, "KWD"),
+			log.Money("min", math.MinInt64, "USD"))
+	})
+	// THEN
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"amount":{"minorUnits":9007199254740993,"currency":"EUR","formatted":"90071992547409.93 EUR"}`)
+	assert.Contains(t, lines[0], `"refund":{"minorUnits":-5,"currency":"EUR","formatted":"-0.05 EUR"}`)
+	assert.Contains(t, lines[0], `"fee":{"minorUnits":1500,"currency":"JPY","formatted":"1500 JPY"}`)
+	assert.Contains(t, lines[0], `"tax":{"minorUnits":1234,"currency":"KWD","formatted":"1.234 KWD"}`)
+	assert.Contains(t, lines[0], `"min":{"minorUnits":-9223372036854775808,"currency":"USD","formatted":"-92233720368547758.08 USD"}`)
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// SetTestCore makes the package logger write to core instead of the configured outputs until restore is called.
+// The entries keep the resource fields, hooks and redaction of the last Init but aren't sampled, routed or exported,
+// nor written to the cores of WithCores. SetLevel applies to core while it's set.
+// Meant for tests only, the package logger is global so tests using it mustn't run in parallel.
+func SetTestCore(core zapcore.Core) (restore func()) {
+	logger()
+	logMu.Lock()
+	defer logMu.Unlock()
+	previousLog, previousBase, previousInit, previousLevel := log, baseLog, initLog, level
+	previousTrace, previousLambda := traceLogFields, lambdaLogFields
+
+	config := logConfig.WithoutSampling()
+	config.routingField, config.otlpExporter, config.cores = "", nil, nil
+	level = zap.NewAtomicLevelAt(zapcore.LevelOf(core))
+	core = &atomicLevelCore{Core: core, level: level}
+	configured := configureLogger(zap.New(core, zap.AddCaller(), wrapCore(config)), config)
+	log, baseLog, initLog = configured, configured, configured
+	std = nil
+	traceLogFields, lambdaLogFields = nil, nil
+
+	return func() {
+		logMu.Lock()
+		defer logMu.Unlock()
+		log, baseLog, initLog, level = previousLog, previousBase, previousInit, previousLevel
+		traceLogFields, lambdaLogFields = previousTrace, previousLambda
+		std = nil
+	}
+}
+
+// atomicLevelCore takes the entries level enables, whatever the level of the core it writes to, so SetLevel
+// applies to the test core.
+type atomicLevelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func (c *atomicLevelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *atomicLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &atomicLevelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *atomicLevelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// ObserveLogs captures the entries logged at enabled or above until restore is called, see SetTestCore.
+func ObserveLogs(enabled zapcore.Level) (logs *observer.ObservedLogs, restore func()) {
+	core, logs := observer.New(enabled)
+	return logs, SetTestCore(core)
+}
@@ -0,0 +1,80 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"path/filepath"
+	"testing"
+)
+
+func TestObserveLogs(t *testing.T) {
+	// GIVEN
+	log.Init(testConfiguration("INFO"))
+	logs, restore := log.ObserveLogs(zapcore.DebugLevel)
+	// WHEN
+	log.Debug("Loading booking")
+	log.ErrorW("Payment failed", "Body.payment.id", "P-1")
+	restore()
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.Info("Back to stderr")
+	})
+	// THEN
+	require.Equal(t, 2, logs.Len())
+	failures := logs.FilterMessage("Payment failed").All()
+	require.Len(t, failures, 1)
+	assert.Equal(t, zapcore.ErrorLevel, failures[0].Level)
+	assert.Equal(t, "P-1", failures[0].ContextMap()["Body.payment.id"])
+	assert.Equal(t, "test-application", failures[0].ContextMap()[log.Application])
+	assert.Len(t, lines, 1)
+}
+
+func TestObserveLogsRestoresLogger(t *testing.T) {
+	// GIVEN
+	var observed int
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.With("Body.booking.id", "B-1")
+		logs, restore := log.ObserveLogs(zapcore.InfoLevel)
+		log.Info("Observed")
+		observed = logs.Len()
+		// WHEN
+		restore()
+		log.Info("Written")
+	})
+	// THEN
+	assert.Equal(t, 1, observed)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"Body.message":"Written"`)
+	assert.Contains(t, lines[0], `"Body.booking.id":"B-1"`)
+}
+
+func TestObserveLogsFollowsSetLevel(t *testing.T) {
+	// GIVEN
+	log.Init(testConfiguration("INFO"))
+	logs, restore := log.ObserveLogs(zapcore.InfoLevel)
+	defer restore()
+	// WHEN
+	log.Debug("Dropped")
+	require.NoError(t, log.SetLevel("DEBUG"))
+	log.Debug("Observed")
+	// THEN
+	assert.Equal(t, "DEBUG", log.GetLevel())
+	assert.True(t, log.IsDebugEnabled())
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "Observed", logs.All()[0].Message)
+}
+
+func TestObserveLogsSkipsExtraCores(t *testing.T) {
+	// GIVEN
+	extra, extraLogs := observer.New(zapcore.DebugLevel)
+	log.Init(testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "app.log")).WithCores(extra))
+	logs, restore := log.ObserveLogs(zapcore.InfoLevel)
+	// WHEN
+	log.Info("Observed")
+	restore()
+	// THEN
+	assert.Equal(t, 1, logs.Len())
+	assert.Equal(t, 0, extraLogs.Len())
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"errors"
+	"go.uber.org/multierr"
+	"strings"
+)
+
+// Option sets a field of the Configuration built by BuildConfiguration.
+type Option func(*Configuration)
+
+// WithLevel sets the log level, INFO when not given.
+func WithLevel(logLevel string) Option {
+	return func(c *Configuration) {
+		c.logLevel = strings.ToUpper(logLevel)
+	}
+}
+
+// WithApplication sets the Application field, required.
+func WithApplication(application string) Option {
+	return func(c *Configuration) {
+		c.application = strings.ToLower(application)
+	}
+}
+
+// WithProject sets the Project field, required.
+func WithProject(project string) Option {
+	return func(c *Configuration) {
+		c.project = strings.ToLower(project)
+	}
+}
+
+// WithProjectGroup sets the ProjectGroup field.
+func WithProjectGroup(projectGroup string) Option {
+	return func(c *Configuration) {
+		c.projectGroup = strings.ToLower(projectGroup)
+	}
+}
+
+// WithVersion sets the Version field, the Lambda function version when not given.
+func WithVersion(version string) Option {
+	return func(c *Configuration) {
+		if version != "" {
+			c.version = version
+		}
+	}
+}
+
+// WithCustomAttributesPrefix sets the prefix of the keys added through WithCustomAttr.
+func WithCustomAttributesPrefix(prefix string) Option {
+	return func(c *Configuration) {
+		c.customAttributesPrefix = strings.ToLower(prefix)
+	}
+}
+
+// WithSamplingDisabled writes every entry instead of sampling repeated ones.
+func WithSamplingDisabled() Option {
+	return func(c *Configuration) {
+		*c = c.WithoutSampling()
+	}
+}
+
+// WithOutputs sets the paths entries are written to, see Configuration.WithOutputPaths.
+func WithOutputs(paths ...string) Option {
+	return func(c *Configuration) {
+		*c = c.WithOutputPaths(paths...)
+	}
+}
+
+// BuildConfiguration applies opts and validates the result as ValidateConfiguration does,
+// reporting every missing required field at once.
+func BuildConfiguration(opts ...Option) (Configuration, error) {
+	config := NewConfiguration("", "", "", "", "", "")
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var errs error
+	if config.application == "" {
+		errs = multierr.Append(errs, errors.New("missing application"))
+	}
+	if config.project == "" {
+		errs = multierr.Append(errs, errors.New("missing project"))
+	}
+	if errs == nil {
+		errs = ValidateConfiguration(config)
+	}
+	return config, errs
+}
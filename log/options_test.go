@@ -0,0 +1,50 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildConfiguration(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "app.log")
+	config, err := log.BuildConfiguration(
+		log.WithLevel("debug"),
+		log.WithApplication("Test-Application"),
+		log.WithProject("Test-Project"),
+		log.WithProjectGroup("Test-Group"),
+		log.WithVersion("1.2.3"),
+		log.WithSamplingDisabled(),
+		log.WithOutputs(path))
+	require.NoError(t, err)
+	// WHEN
+	instance, err := log.New(config)
+	require.NoError(t, err)
+	instance.Debug("Booted")
+	require.NoError(t, instance.Flush())
+	// THEN
+	entries := readEntries(t, path)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "DEBUG", entries[0][log.Level])
+	assert.Equal(t, "test-application", entries[0][log.Application])
+	assert.Equal(t, "test-project", entries[0][log.Project])
+	assert.Equal(t, "test-group", entries[0][log.ProjectGroup])
+	assert.Equal(t, "1.2.3", entries[0][log.Version])
+}
+
+func TestBuildConfigurationReportsMissingFields(t *testing.T) {
+	// WHEN
+	_, err := log.BuildConfiguration(log.WithLevel("INFO"))
+	// THEN
+	assert.EqualError(t, err, "missing application; missing project")
+}
+
+func TestBuildConfigurationValidates(t *testing.T) {
+	// WHEN
+	_, err := log.BuildConfiguration(log.WithApplication("app"), log.WithProject("project"), log.WithLevel("LOUD"))
+	// THEN
+	assert.ErrorContains(t, err, "malformed log level: LOUD")
+}
@@ -0,0 +1,365 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// otlpBatchSize is the number of buffered records which triggers an export ahead of Sync, in the background.
+	otlpBatchSize = 512
+	// otlpMaxPendingBatches is the number of batches waiting for the background export past which the full
+	// batches are dropped, so a stalled collector doesn't pile them up in memory.
+	otlpMaxPendingBatches = 4
+	// otlpExportTimeout bounds every export, in the background and on Sync.
+	otlpExportTimeout = 10 * time.Second
+)
+
+const resourcePrefix = "Resource."
+
+// LogExporter ships batches of log records to an OpenTelemetry collector.
+type LogExporter interface {
+	Export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error
+}
+
+// WithOTLPExport sends every entry to exporter as well as to the configured outputs. A nil exporter posts the records
+// over OTLP/HTTP to OTEL_EXPORTER_OTLP_LOGS_ENDPOINT or, failing that, to OTEL_EXPORTER_OTLP_ENDPOINT + "/v1/logs".
+// Records are exported in batches in the background, Flush waits for them and exports the pending ones.
+// Full batches are dropped while a few are still waiting for a slow collector, Flush reports how many records were.
+func (c Configuration) WithOTLPExport(exporter LogExporter) Configuration {
+	if exporter == nil {
+		exporter = NewHTTPLogExporter("")
+	}
+	c.otlpExporter = exporter
+	return c
+}
+
+type httpLogExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPLogExporter returns a LogExporter posting protobuf encoded requests to endpoint,
+// an empty endpoint is taken from the OTEL_EXPORTER_OTLP environment variables.
+func NewHTTPLogExporter(endpoint string) LogExporter {
+	if endpoint == "" {
+		endpoint = otlpLogsEndpoint()
+	}
+	return &httpLogExporter{endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func otlpLogsEndpoint() string {
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://localhost:4318"
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/v1/logs"
+}
+
+func (e *httpLogExporter) Export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("exporting logs to %s: %s", e.endpoint, resp.Status)
+	}
+	return nil
+}
+
+// otlpBuffer holds the records written through all the otlpCores sharing an exporter until they're exported.
+// Full batches are exported in the background, one after the other, without blocking the logging goroutine.
+type otlpBuffer struct {
+	mu       sync.Mutex
+	exporter LogExporter
+	records  []otlpRecord
+	// exported is closed once the last batch handed off to the background is exported.
+	exported chan struct{}
+	// pending is the number of batches handed off to the background and not exported yet.
+	pending int
+	// dropped is the number of records dropped since the last Sync, past otlpMaxPendingBatches.
+	dropped int
+	// err holds the failures of the background exports until the next Sync reports them.
+	err error
+}
+
+// handOff exports records in the background after the batches handed off before, or drops them when
+// otlpMaxPendingBatches are already waiting. b.mu has to be held.
+func (b *otlpBuffer) handOff(records []otlpRecord) {
+	if b.pending >= otlpMaxPendingBatches {
+		b.dropped += len(records)
+		return
+	}
+	b.pending++
+	previous, exported := b.exported, make(chan struct{})
+	b.exported = exported
+	go func() {
+		defer close(exported)
+		if previous != nil {
+			<-previous
+		}
+		err := b.export(records)
+		b.mu.Lock()
+		b.pending--
+		b.err = multierr.Append(b.err, err)
+		b.mu.Unlock()
+	}()
+}
+
+func (b *otlpBuffer) export(records []otlpRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+	defer cancel()
+	return b.exporter.Export(ctx, exportRequest(records))
+}
+
+type otlpRecord struct {
+	resource []*commonpb.KeyValue
+	record   *logspb.LogRecord
+}
+
+// otlpCore converts entries to OTLP log records. Fields prefixed with Resource. become resource attributes,
+// the trace fields set the trace and span id of the record.
+type otlpCore struct {
+	zapcore.LevelEnabler
+	buffer *otlpBuffer
+	fields []zapcore.Field
+}
+
+func newOTLPCore(exporter LogExporter, enabler zapcore.LevelEnabler) *otlpCore {
+	return &otlpCore{LevelEnabler: enabler, buffer: &otlpBuffer{exporter: exporter}}
+}
+
+func (c *otlpCore) With(fields []zapcore.Field) zapcore.Core {
+	return &otlpCore{
+		LevelEnabler: c.LevelEnabler,
+		buffer:       c.buffer,
+		fields:       append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *otlpCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *otlpCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(entry.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       severityNumber(entry.Level),
+		SeverityText:         entry.Level.CapitalString(),
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: entry.Message}},
+	}
+	var resource []*commonpb.KeyValue
+	for _, key := range sortedKeys(encoder.Fields) {
+		value := encoder.Fields[key]
+		switch key {
+		case TraceId:
+			record.TraceId = decodeHexId(value, 16)
+		case SpanId, GCPSpanId:
+			record.SpanId = decodeHexId(value, 8)
+		case GCPTrace:
+			if s, ok := value.(string); ok {
+				record.TraceId = decodeHexId(s[strings.LastIndexByte(s, '/')+1:], 16)
+			}
+		}
+		if strings.HasPrefix(key, resourcePrefix) {
+			resource = append(resource, keyValue(strings.TrimPrefix(key, resourcePrefix), value))
+			continue
+		}
+		record.Attributes = append(record.Attributes, keyValue(key, value))
+	}
+	if entry.Stack != "" {
+		record.Attributes = append(record.Attributes, keyValue(StackTrace, entry.Stack))
+	}
+
+	c.buffer.mu.Lock()
+	c.buffer.records = append(c.buffer.records, otlpRecord{resource: resource, record: record})
+	if len(c.buffer.records) >= otlpBatchSize && entry.Level <= zapcore.ErrorLevel {
+		c.buffer.handOff(c.buffer.records)
+		c.buffer.records = nil
+	}
+	c.buffer.mu.Unlock()
+	if entry.Level > zapcore.ErrorLevel {
+		return c.Sync()
+	}
+	return nil
+}
+
+// Sync waits for the batches handed off to the background and exports the pending records.
+func (c *otlpCore) Sync() error {
+	c.buffer.mu.Lock()
+	records, exported := c.buffer.records, c.buffer.exported
+	c.buffer.records = nil
+	c.buffer.mu.Unlock()
+	if exported != nil {
+		<-exported
+	}
+	var err error
+	if len(records) > 0 {
+		err = c.buffer.export(records)
+	}
+	c.buffer.mu.Lock()
+	err, c.buffer.err = multierr.Append(c.buffer.err, err), nil
+	if c.buffer.dropped > 0 {
+		err = multierr.Append(err, fmt.Errorf("dropped %d OTLP log records, the export falls behind", c.buffer.dropped))
+		c.buffer.dropped = 0
+	}
+	c.buffer.mu.Unlock()
+	return err
+}
+
+// exportRequest groups records by resource, keeping the order they were written in.
+func exportRequest(records []otlpRecord) *collogspb.ExportLogsServiceRequest {
+	request := &collogspb.ExportLogsServiceRequest{}
+	byResource := map[string]*logspb.ScopeLogs{}
+	for _, r := range records {
+		key := resourceKey(r.resource)
+		scope, ok := byResource[key]
+		if !ok {
+			scope = &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: "github.com/Ryanair/gofrlib/log"}}
+			byResource[key] = scope
+			request.ResourceLogs = append(request.ResourceLogs, &logspb.ResourceLogs{
+				Resource:  &resourcepb.Resource{Attributes: r.resource},
+				ScopeLogs: []*logspb.ScopeLogs{scope},
+			})
+		}
+		scope.LogRecords = append(scope.LogRecords, r.record)
+	}
+	return request
+}
+
+func resourceKey(resource []*commonpb.KeyValue) string {
+	var key strings.Builder
+	for _, kv := range resource {
+		fmt.Fprintf(&key, "%s=%v;", kv.Key, kv.Value)
+	}
+	return key.String()
+}
+
+func severityNumber(level zapcore.Level) logspb.SeverityNumber {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case level == zapcore.InfoLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case level == zapcore.WarnLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case level == zapcore.ErrorLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	}
+}
+
+func decodeHexId(value interface{}, size int) []byte {
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	id, err := hex.DecodeString(s)
+	if err != nil || len(id) != size {
+		return nil
+	}
+	return id
+}
+
+func keyValue(key string, value interface{}) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: anyValue(value)}
+}
+
+func anyValue(value interface{}) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int8:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int16:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+	case uint8:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case uint16:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case uint32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case float32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: float64(v)}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case time.Time:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Format(time.RFC3339Nano)}}
+	case []byte:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: v}}
+	case []interface{}:
+		values := make([]*commonpb.AnyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, anyValue(item))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]interface{}:
+		values := make([]*commonpb.KeyValue, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			values = append(values, keyValue(key, v[key]))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: values}}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,225 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockLogExporter struct {
+	mu       sync.Mutex
+	requests []*collogspb.ExportLogsServiceRequest
+}
+
+func (e *mockLogExporter) Export(_ context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests = append(e.requests, request)
+	return nil
+}
+
+func (e *mockLogExporter) records() []*logspb.LogRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var records []*logspb.LogRecord
+	for _, request := range e.requests {
+		for _, resourceLogs := range request.ResourceLogs {
+			for _, scopeLogs := range resourceLogs.ScopeLogs {
+				records = append(records, scopeLogs.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func TestWithOTLPExport(t *testing.T) {
+	// GIVEN
+	exporter := &mockLogExporter{}
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO").WithOTLPExport(exporter), func() {
+		log.DebugW("Dropped")
+		log.LoggerFromContext(ctx).Warnw("Booking delayed", "Body.booking.id", "B-1", "Body.booking.seats", 2)
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	records := exporter.records()
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "Booking delayed", record.Body.GetStringValue())
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_WARN, record.SeverityNumber)
+	assert.Equal(t, traceId[:], record.TraceId)
+	assert.Equal(t, spanId[:], record.SpanId)
+	attributes := map[string]*commonpb.AnyValue{}
+	for _, kv := range record.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "B-1", attributes["Body.booking.id"].GetStringValue())
+	assert.Equal(t, int64(2), attributes["Body.booking.seats"].GetIntValue())
+
+	resource := map[string]string{}
+	for _, kv := range exporter.requests[0].ResourceLogs[0].Resource.Attributes {
+		resource[kv.Key] = kv.Value.GetStringValue()
+	}
+	assert.Equal(t, "test-application", resource["application"])
+	assert.NotEmpty(t, resource["service.name"])
+}
+
+func TestWithOTLPExportDisabledByDefault(t *testing.T) {
+	// GIVEN
+	received := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.Info("Not exported")
+	})
+	// THEN
+	assert.Len(t, lines, 1)
+	assert.False(t, received)
+}
+
+func TestNewHTTPLogExporterUsesEnvironmentEndpoint(t *testing.T) {
+	// GIVEN
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+	}))
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL+"/")
+	// WHEN
+	captureLines(t, testConfiguration("INFO").WithOTLPExport(nil), func() {
+		log.Info("Exported over http")
+	})
+	// THEN
+	r := <-requests
+	assert.Equal(t, "/v1/logs", r.URL.Path)
+	assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+	var request collogspb.ExportLogsServiceRequest
+	require.NoError(t, proto.Unmarshal(<-bodies, &request))
+	assert.Equal(t, "Exported over http",
+		request.ResourceLogs[0].ScopeLogs[0].LogRecords[0].Body.GetStringValue())
+}
+
+// blockingLogExporter holds every export until release is closed.
+type blockingLogExporter struct {
+	mockLogExporter
+	release chan struct{}
+}
+
+func (e *blockingLogExporter) Export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	<-e.release
+	return e.mockLogExporter.Export(ctx, request)
+}
+
+func TestWithOTLPExportHandsFullBatchesOff(t *testing.T) {
+	// GIVEN
+	exporter := &blockingLogExporter{release: make(chan struct{})}
+	logger, err := log.New(testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "app.log")).WithOTLPExport(exporter))
+	require.NoError(t, err)
+	// WHEN
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < 600; i++ {
+			logger.Info("Booking %d confirmed", i)
+		}
+	}()
+	// THEN
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging blocked on the export")
+	}
+	assert.Empty(t, exporter.records())
+	close(exporter.release)
+	require.NoError(t, logger.Flush())
+	records := exporter.records()
+	require.Len(t, records, 600)
+	assert.Equal(t, "Booking 0 confirmed", records[0].Body.GetStringValue())
+	assert.Equal(t, "Booking 599 confirmed", records[599].Body.GetStringValue())
+}
+
+func TestWithOTLPExportDropsBatchesPastTheQueue(t *testing.T) {
+	// GIVEN
+	exporter := &blockingLogExporter{release: make(chan struct{})}
+	logger, err := log.New(testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "app.log")).WithOTLPExport(exporter))
+	require.NoError(t, err)
+	// WHEN
+	for i := 0; i < 6*512; i++ {
+		logger.Info("Booking %d confirmed", i)
+	}
+	close(exporter.release)
+	err = logger.Flush()
+	// THEN
+	assert.ErrorContains(t, err, "dropped 1024 OTLP log records")
+	assert.Len(t, exporter.records(), 4*512)
+	assert.NoError(t, logger.Flush())
+}
+
+func TestInitExportsRecordsOfReplacedLogger(t *testing.T) {
+	// GIVEN
+	exporter := &mockLogExporter{}
+	dir := t.TempDir()
+	require.NoError(t, log.InitE(testConfiguration("INFO").WithOutputPaths(filepath.Join(dir, "first.log")).WithOTLPExport(exporter)))
+	log.Info("Logged before Init")
+	// WHEN
+	require.NoError(t, log.InitE(testConfiguration("INFO").WithOutputPaths(filepath.Join(dir, "second.log"))))
+	// THEN
+	records := exporter.records()
+	require.Len(t, records, 1)
+	assert.Equal(t, "Logged before Init", records[0].Body.GetStringValue())
+}
+
+// deadlineLogExporter records whether the exports run with a deadline.
+type deadlineLogExporter struct {
+	mu        sync.Mutex
+	deadlines []bool
+}
+
+func (e *deadlineLogExporter) Export(ctx context.Context, _ *collogspb.ExportLogsServiceRequest) error {
+	_, ok := ctx.Deadline()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deadlines = append(e.deadlines, ok)
+	return nil
+}
+
+func TestWithOTLPExportSetsDeadline(t *testing.T) {
+	// GIVEN
+	exporter := &deadlineLogExporter{}
+	logger, err := log.New(testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "app.log")).WithOTLPExport(exporter))
+	require.NoError(t, err)
+	// WHEN
+	for i := 0; i < 600; i++ {
+		logger.Info("Booking %d confirmed", i)
+	}
+	require.NoError(t, logger.Flush())
+	// THEN
+	assert.Equal(t, []bool{true, true}, exporter.deadlines)
+}
@@ -0,0 +1,58 @@
+package log_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func testConfiguration(logLevel string) log.Configuration {
+	return log.NewConfiguration(
+		logLevel,
+		"TEST-APPLICATION",
+		"TEST-PROJECT",
+		"TEST-PROJECT-GROUP",
+		"1.0.0",
+		"testPrefix")
+}
+
+// captureOutput initialises the logger with config, runs fn and returns the decoded JSON entries it wrote.
+func captureOutput(t *testing.T, config log.Configuration, fn func()) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range captureLines(t, config, fn) {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// captureLines initialises the logger with config, runs fn and returns the lines it wrote.
+func captureLines(t *testing.T, config log.Configuration, fn func()) []string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "log")
+	require.NoError(t, err)
+	defer file.Close()
+
+	stderr := os.Stderr
+	os.Stderr = file
+	log.Init(config)
+	os.Stderr = stderr
+
+	fn()
+	_ = log.Flush()
+
+	_, err = file.Seek(0, 0)
+	require.NoError(t, err)
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
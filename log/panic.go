@@ -4,7 +4,7 @@ import "os"
 
 func HandlePanic() {
 	if r := recover(); r != nil {
-		log.Error("Panic occurred: %v", r)
+		logger().Error("Panic occurred: %v", r)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync"
+)
+
+var (
+	promotedMu   sync.RWMutex
+	promotedKeys = map[string]bool{}
+)
+
+// PromoteToSpan copies the fields logged under keys through the ...WCtx functions onto the span of the context,
+// keeping logs and traces consistent without writing the values twice. The values are redacted as in the logs.
+func PromoteToSpan(keys ...string) {
+	promotedMu.Lock()
+	defer promotedMu.Unlock()
+	for _, key := range keys {
+		promotedKeys[key] = true
+	}
+}
+
+// ctxLogger returns the logger of l for ctx along with the expanded keysAndValues,
+// promoting the configured keys to the span of ctx on the way.
+func (l *Instance) ctxLogger(ctx context.Context, keysAndValues []interface{}) (*zap.SugaredLogger, []interface{}) {
+	keysAndValues = expandKeysAndValues(keysAndValues)
+	promote(ctx, keysAndValues)
+	return l.fromContext(ctx), keysAndValues
+}
+
+func promote(ctx context.Context, keysAndValues []interface{}) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	promotedMu.RLock()
+	defer promotedMu.RUnlock()
+	if len(promotedKeys) == 0 {
+		return
+	}
+	var attrs []attribute.KeyValue
+	for i := 0; i < len(keysAndValues); i++ {
+		switch value := keysAndValues[i].(type) {
+		case zap.Field:
+			if promotedKeys[value.Key] {
+				attrs = append(attrs, ToAttribute(value.Key, redactValue(value.Key, fieldValue(value))))
+			}
+		case string:
+			if i+1 < len(keysAndValues) {
+				if promotedKeys[value] {
+					attrs = append(attrs, ToAttribute(value, redactValue(value, keysAndValues[i+1])))
+				}
+				i++
+			}
+		}
+	}
+	span.SetAttributes(attrs...)
+}
+
+// fieldValue returns the value held by a zap.Field, as it would be encoded.
+func fieldValue(field zap.Field) interface{} {
+	encoder := zapcore.NewMapObjectEncoder()
+	field.AddTo(encoder)
+	return encoder.Fields[field.Key]
+}
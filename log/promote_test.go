@@ -0,0 +1,75 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestPromoteToSpan(t *testing.T) {
+	// GIVEN
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "booking")
+	log.PromoteToSpan("Body.booking.id", "Body.booking.passengers")
+	defer log.ResetPromotion()
+
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.InfoWCtx(ctx, "Booking confirmed",
+			"Body.booking.id", "ABC123",
+			zap.Int("Body.booking.passengers", 2),
+			"Body.booking.channel", "web")
+	})
+	span.End()
+
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ABC123", entries[0]["Body.booking.id"])
+	assert.Equal(t, float64(2), entries[0]["Body.booking.passengers"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), entries[0][log.TraceId])
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, attribute.StringValue("ABC123"), attrs["Body.booking.id"])
+	assert.Equal(t, attribute.Int64Value(2), attrs["Body.booking.passengers"])
+	assert.NotContains(t, attrs, attribute.Key("Body.booking.channel"))
+}
+
+func TestPromoteToSpanRedacts(t *testing.T) {
+	// GIVEN
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "booking")
+	log.PromoteToSpan("Body.customer.email", "Body.booking.seats")
+	defer log.ResetPromotion()
+	log.SetRedactedKeys([]string{"email"})
+	defer log.SetRedactedKeys(nil)
+
+	// WHEN
+	captureOutput(t, testConfiguration("INFO"), func() {
+		log.InfoWCtx(ctx, "Booking confirmed",
+			"Body.customer.email", "jane@example.com",
+			"Body.booking.seats", []string{"1A", "1B"})
+	})
+	span.End()
+
+	// THEN
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, attribute.StringValue(log.RedactedValue), attrs["Body.customer.email"])
+	assert.Equal(t, attribute.StringSliceValue([]string{"1A", "1B"}), attrs["Body.booking.seats"])
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"go.uber.org/zap"
+)
+
+// LogContextProvider is implemented by values which contribute their own fields to log entries, so a domain object
+// can be passed to the ...W functions without the caller knowing its internals.
+// Passed on its own the fields are added as returned, passed as the value of a key they are prefixed with "<key>.".
+type LogContextProvider interface {
+	LogContext() []interface{}
+}
+
+func expandKeysAndValues(keysAndValues []interface{}) []interface{} {
+	if !containsProvider(keysAndValues) {
+		return keysAndValues
+	}
+
+	expanded := make([]interface{}, 0, len(keysAndValues))
+	for i := 0; i < len(keysAndValues); i++ {
+		switch value := keysAndValues[i].(type) {
+		case LogContextProvider:
+			expanded = append(expanded, value.LogContext()...)
+		case zap.Field:
+			expanded = append(expanded, value)
+		default:
+			if i+1 == len(keysAndValues) {
+				expanded = append(expanded, value)
+				break
+			}
+			key, isKey := value.(string)
+			if provider, isProvider := keysAndValues[i+1].(LogContextProvider); isKey && isProvider {
+				expanded = append(expanded, prefixKeys(key, provider.LogContext())...)
+			} else {
+				expanded = append(expanded, value, keysAndValues[i+1])
+			}
+			i++
+		}
+	}
+	return expanded
+}
+
+func containsProvider(keysAndValues []interface{}) bool {
+	for _, value := range keysAndValues {
+		if _, ok := value.(LogContextProvider); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func prefixKeys(prefix string, keysAndValues []interface{}) []interface{} {
+	prefixed := make([]interface{}, 0, len(keysAndValues))
+	for i := 0; i < len(keysAndValues); i++ {
+		switch value := keysAndValues[i].(type) {
+		case zap.Field:
+			value.Key = prefix + "." + value.Key
+			prefixed = append(prefixed, value)
+		case string:
+			prefixed = append(prefixed, prefix+"."+value)
+			if i+1 < len(keysAndValues) {
+				prefixed = append(prefixed, keysAndValues[i+1])
+				i++
+			}
+		default:
+			prefixed = append(prefixed, value)
+		}
+	}
+	return prefixed
+}
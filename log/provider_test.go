@@ -0,0 +1,37 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type booking struct {
+	id     string
+	flight string
+	seats  int
+}
+
+func (b booking) LogContext() []interface{} {
+	return []interface{}{"bookingId", b.id, "flight", b.flight, zap.Int("seats", b.seats)}
+}
+
+func TestLogContextProvider(t *testing.T) {
+	// GIVEN
+	b := booking{id: "B-1", flight: "FR1234", seats: 2}
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.InfoW("Booking confirmed", b, "channel", "web")
+		log.WarnW("Booking delayed", "booking", b)
+	})
+	// THEN
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "B-1", entries[0]["bookingId"])
+	assert.Equal(t, "FR1234", entries[0]["flight"])
+	assert.Equal(t, float64(2), entries[0]["seats"])
+	assert.Equal(t, "web", entries[0]["channel"])
+	assert.Equal(t, "B-1", entries[1]["booking.bookingId"])
+	assert.Equal(t, "FR1234", entries[1]["booking.flight"])
+	assert.Equal(t, float64(2), entries[1]["booking.seats"])
+}
@@ -0,0 +1,195 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const RedactedValue = "***"
+
+var (
+	redactMu         sync.RWMutex
+	redactedKeys     map[string]bool
+	redactedPatterns []*regexp.Regexp
+	// redactGeneration counts the redaction changes, for the cores to redact again the fields attached before.
+	redactGeneration atomic.Uint64
+)
+
+// SetRedactedKeys replaces the values of the fields named one of keys with RedactedValue, fields attached through
+// With included, also when attached before the keys were set. Keys match case-insensitively either the whole field
+// key or its last segment, so "email" covers "Body.customer.email" as well.
+func SetRedactedKeys(keys []string) {
+	redacted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		redacted[strings.ToLower(key)] = true
+	}
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redactedKeys = redacted
+	redactGeneration.Add(1)
+}
+
+// RegisterRedaction adds keys to the redacted ones, matched as in SetRedactedKeys.
+func RegisterRedaction(keys ...string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redacted := make(map[string]bool, len(redactedKeys)+len(keys))
+	for key := range redactedKeys {
+		redacted[key] = true
+	}
+	for _, key := range keys {
+		redacted[strings.ToLower(key)] = true
+	}
+	redactedKeys = redacted
+	redactGeneration.Add(1)
+}
+
+// RegisterRedactionPattern replaces the substrings matching one of patterns with RedactedValue, in the messages
+// and in the string field values, whatever their key, e.g. to mask card numbers wherever they end up.
+// Values nested in objects and arrays aren't scanned, redact those by key.
+func RegisterRedactionPattern(patterns ...*regexp.Regexp) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redactedPatterns = append(redactedPatterns[:len(redactedPatterns):len(redactedPatterns)], patterns...)
+	redactGeneration.Add(1)
+}
+
+// redactPatterns masks the substrings of s matching the redacted patterns, redactMu has to be held.
+func redactPatterns(s string) string {
+	for _, pattern := range redactedPatterns {
+		s = pattern.ReplaceAllLiteralString(s, RedactedValue)
+	}
+	return s
+}
+
+func isRedacted(key string) bool {
+	key = strings.ToLower(key)
+	if redactedKeys[key] {
+		return true
+	}
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		return redactedKeys[key[i+1:]]
+	}
+	return false
+}
+
+// redactionCore masks the values of the redacted fields, and the redacted patterns of the message,
+// before they reach the encoder. It keeps the fields attached through With unredacted, to attach them again
+// to the core beneath when the redaction changes.
+type redactionCore struct {
+	root   zapcore.Core
+	fields []zapcore.Field
+	state  atomic.Pointer[redactedCore]
+}
+
+// redactedCore is root with the fields attached through With redacted as of generation.
+type redactedCore struct {
+	zapcore.Core
+	generation uint64
+}
+
+func newRedactionCore(core zapcore.Core) *redactionCore {
+	c := &redactionCore{root: core}
+	c.state.Store(&redactedCore{Core: core, generation: redactGeneration.Load()})
+	return c
+}
+
+// current returns root with the fields attached through With redacted as of now.
+func (c *redactionCore) current() zapcore.Core {
+	generation := redactGeneration.Load()
+	if state := c.state.Load(); state.generation == generation {
+		return state.Core
+	}
+	core := c.root
+	if len(c.fields) > 0 {
+		core = core.With(redactFields(c.fields))
+	}
+	c.state.Store(&redactedCore{Core: core, generation: generation})
+	return core
+}
+
+func (c *redactionCore) Enabled(level zapcore.Level) bool {
+	return c.root.Enabled(level)
+}
+
+func (c *redactionCore) With(fields []zapcore.Field) zapcore.Core {
+	derived := &redactionCore{root: c.root, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+	generation := redactGeneration.Load()
+	derived.state.Store(&redactedCore{Core: c.current().With(redactFields(fields)), generation: generation})
+	return derived
+}
+
+func (c *redactionCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactionCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	redactMu.RLock()
+	entry.Message = redactPatterns(entry.Message)
+	redactMu.RUnlock()
+	return c.current().Write(entry, redactFields(fields))
+}
+
+func (c *redactionCore) Sync() error {
+	return c.root.Sync()
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	if len(redactedKeys) == 0 && len(redactedPatterns) == 0 {
+		return fields
+	}
+
+	var redacted []zapcore.Field
+	for i, field := range fields {
+		replacement, ok := redactField(field)
+		if !ok {
+			if redacted != nil {
+				redacted = append(redacted, field)
+			}
+			continue
+		}
+		if redacted == nil {
+			redacted = append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+		}
+		redacted = append(redacted, replacement)
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// redactValue returns value as the redaction core would write it under key.
+func redactValue(key string, value interface{}) interface{} {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	if isRedacted(key) {
+		return RedactedValue
+	}
+	if s, ok := value.(string); ok {
+		return redactPatterns(s)
+	}
+	return value
+}
+
+// redactField returns the masked field replacing field, if any, redactMu has to be held.
+func redactField(field zapcore.Field) (zapcore.Field, bool) {
+	if isRedacted(field.Key) {
+		return zap.String(field.Key, RedactedValue), true
+	}
+	if field.Type == zapcore.StringType && len(redactedPatterns) > 0 {
+		if value := redactPatterns(field.String); value != field.String {
+			return zap.String(field.Key, value), true
+		}
+	}
+	return field, false
+}
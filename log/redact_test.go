@@ -0,0 +1,80 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSetRedactedKeys(t *testing.T) {
+	// GIVEN
+	log.SetRedactedKeys([]string{"email", "Authorization", "SSN"})
+	defer log.SetRedactedKeys(nil)
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.With("Body.customer.Email", "jane@example.com")
+		log.InfoW("Customer updated",
+			"authorization", "Bearer secret-token",
+			zap.String("ssn", "123-45-6789"),
+			"Body.customer.id", "C-1")
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	for _, secret := range []string{"jane@example.com", "secret-token", "123-45-6789"} {
+		assert.False(t, strings.Contains(lines[0], secret), lines[0])
+	}
+	assert.Contains(t, lines[0], `"Body.customer.Email":"***"`)
+	assert.Contains(t, lines[0], `"authorization":"***"`)
+	assert.Contains(t, lines[0], `"ssn":"***"`)
+	assert.Contains(t, lines[0], `"Body.customer.id":"C-1"`)
+}
+
+func TestRegisterRedaction(t *testing.T) {
+	// GIVEN
+	log.SetRedactedKeys([]string{"password"})
+	log.RegisterRedaction("SSN")
+	log.RegisterRedactionPattern(regexp.MustCompile(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`))
+	defer log.ResetRedaction()
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.With("Body.note", "card 4111-1111-1111-1111 on file")
+		log.Info("Charging card %s", "4111-1111-1111-1111")
+		log.InfoW("Customer created", "password", "hunter2", "Body.customer.ssn", "123-45-6789", "Body.count", 2)
+	})
+	// THEN
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"Body.message":"Charging card ***"`)
+	assert.Contains(t, lines[0], `"Body.note":"card *** on file"`)
+	assert.Contains(t, lines[1], `"password":"***"`)
+	assert.Contains(t, lines[1], `"Body.customer.ssn":"***"`)
+	assert.Contains(t, lines[1], `"Body.count":2`)
+	for _, line := range lines {
+		assert.NotContains(t, line, "4111")
+	}
+}
+
+func TestRedactionCoversFieldsAttachedBefore(t *testing.T) {
+	// GIVEN
+	defer log.ResetRedaction()
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.With("Body.customer.email", "jane@example.com", "Body.note", "card 4111-1111-1111-1111")
+		log.Info("Before registration")
+		log.RegisterRedaction("email")
+		log.RegisterRedactionPattern(regexp.MustCompile(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`))
+		log.Info("After registration")
+		log.SetRedactedKeys(nil)
+		log.Info("After reset")
+	})
+	// THEN
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"Body.customer.email":"jane@example.com"`)
+	assert.Contains(t, lines[1], `"Body.customer.email":"***"`)
+	assert.Contains(t, lines[1], `"Body.note":"card ***"`)
+	assert.Contains(t, lines[2], `"Body.customer.email":"jane@example.com"`)
+	assert.Contains(t, lines[2], `"Body.note":"card ***"`)
+}
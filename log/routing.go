@@ -0,0 +1,86 @@
+package log
+
+import (
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// routingCore writes each entry to the sink registered for the value of its routing field,
+// falling back to the main core when the field is missing or the value has no sink.
+type routingCore struct {
+	field    string
+	fallback zapcore.Core
+	sinks    map[string]zapcore.Core
+	// route is the value of the routing field when it was already attached through With.
+	route string
+}
+
+// NewRoutingCore returns a core routing entries by the string value of field, e.g. sending
+// entries logged with "channel", "security" to the audit sink registered under "security".
+// Entries without a matching sink go to fallback.
+func NewRoutingCore(field string, fallback zapcore.Core, sinks map[string]zapcore.Core) zapcore.Core {
+	return &routingCore{field: field, fallback: fallback, sinks: sinks}
+}
+
+func (c *routingCore) Enabled(level zapcore.Level) bool {
+	if c.fallback.Enabled(level) {
+		return true
+	}
+	for _, sink := range c.sinks {
+		if sink.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *routingCore) With(fields []zapcore.Field) zapcore.Core {
+	sinks := make(map[string]zapcore.Core, len(c.sinks))
+	for value, sink := range c.sinks {
+		sinks[value] = sink.With(fields)
+	}
+	route := c.route
+	if value, found := c.routeOf(fields); found {
+		route = value
+	}
+	return &routingCore{field: c.field, fallback: c.fallback.With(fields), sinks: sinks, route: route}
+}
+
+func (c *routingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *routingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	route := c.route
+	if value, found := c.routeOf(fields); found {
+		route = value
+	}
+	target, exists := c.sinks[route]
+	if !exists {
+		target = c.fallback
+	}
+	if !target.Enabled(entry.Level) {
+		return nil
+	}
+	return target.Write(entry, fields)
+}
+
+func (c *routingCore) Sync() error {
+	err := c.fallback.Sync()
+	for _, sink := range c.sinks {
+		err = multierr.Append(err, sink.Sync())
+	}
+	return err
+}
+
+func (c *routingCore) routeOf(fields []zapcore.Field) (string, bool) {
+	for _, field := range fields {
+		if field.Key == c.field && field.Type == zapcore.StringType {
+			return field.String, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,49 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestRoutingCore(t *testing.T) {
+	// GIVEN
+	mainCore, mainLogs := observer.New(zapcore.InfoLevel)
+	auditCore, auditLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(log.NewRoutingCore("channel", mainCore, map[string]zapcore.Core{"security": auditCore})).Sugar()
+	// WHEN
+	logger.Infow("Login failed", "channel", "security")
+	logger.Infow("Order placed", "channel", "business")
+	logger.With("channel", "security").Info("Password changed")
+	logger.Info("Started")
+	// THEN
+	assert.Equal(t, []string{"Login failed", "Password changed"}, messages(auditLogs))
+	assert.Equal(t, []string{"Order placed", "Started"}, messages(mainLogs))
+}
+
+func TestInitWithRouting(t *testing.T) {
+	// GIVEN
+	auditCore, auditLogs := observer.New(zapcore.InfoLevel)
+	config := testConfiguration("INFO").WithRouting("channel", map[string]zapcore.Core{"security": auditCore})
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		log.InfoW("Login failed", "channel", "security")
+		log.Info("Started")
+	})
+	// THEN
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Started", entries[0][log.Message])
+	assert.Equal(t, []string{"Login failed"}, messages(auditLogs))
+	assert.Equal(t, "test-application", auditLogs.All()[0].ContextMap()[log.Application])
+}
+
+func messages(logs *observer.ObservedLogs) []string {
+	var msgs []string
+	for _, entry := range logs.All() {
+		msgs = append(msgs, entry.Message)
+	}
+	return msgs
+}
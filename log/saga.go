@@ -0,0 +1,56 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	SagaStatusStarted      = "started"
+	SagaStatusCompleted    = "completed"
+	SagaStatusFailed       = "failed"
+	SagaStatusCompensating = "compensating"
+	SagaStatusCompensated  = "compensated"
+)
+
+var sagaStatuses = map[string]bool{
+	SagaStatusStarted:      true,
+	SagaStatusCompleted:    true,
+	SagaStatusFailed:       true,
+	SagaStatusCompensating: true,
+	SagaStatusCompensated:  true,
+}
+
+var (
+	sagaIdKey     = attribute.Key("saga.id")
+	sagaStepKey   = attribute.Key("saga.step")
+	sagaStatusKey = attribute.Key("saga.status")
+)
+
+// SagaStep logs a step of the distributed transaction sagaID and records it on the current span,
+// so the whole saga can be reconstructed from either logs or traces. status must be one of the SagaStatus constants.
+func SagaStep(ctx context.Context, sagaID, step string, status string) error {
+	if !sagaStatuses[status] {
+		return fmt.Errorf("unknown saga status: %q", status)
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		sagaIdKey.String(sagaID),
+		sagaStepKey.String(step),
+		sagaStatusKey.String(status),
+	)
+
+	keysAndValues := []interface{}{
+		SagaId, sagaID,
+		SagaStepName, step,
+		SagaStatus, status,
+	}
+	if status == SagaStatusFailed {
+		loggerFromContext(ctx).Warnw("Saga step", keysAndValues...)
+	} else {
+		loggerFromContext(ctx).Infow("Saga step", keysAndValues...)
+	}
+	return nil
+}
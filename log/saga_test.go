@@ -0,0 +1,44 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSagaStep(t *testing.T) {
+	// WHEN
+	var err error
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		err = log.SagaStep(context.Background(), "saga-1", "reserve-seat", log.SagaStatusCompleted)
+	})
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "INFO", entries[0][log.Level])
+	assert.Equal(t, "saga-1", entries[0][log.SagaId])
+	assert.Equal(t, "reserve-seat", entries[0][log.SagaStepName])
+	assert.Equal(t, "completed", entries[0][log.SagaStatus])
+}
+
+func TestSagaStepFailedLogsWarn(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		_ = log.SagaStep(context.Background(), "saga-1", "charge-card", log.SagaStatusFailed)
+	})
+	// THEN
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "WARN", entries[0][log.Level])
+}
+
+func TestSagaStepRejectsUnknownStatus(t *testing.T) {
+	// WHEN
+	var err error
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		err = log.SagaStep(context.Background(), "saga-1", "reserve-seat", "done")
+	})
+	// THEN
+	assert.Error(t, err)
+	assert.Empty(t, entries)
+}
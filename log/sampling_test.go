@@ -0,0 +1,84 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+func TestSamplingByDefault(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		for i := 0; i < 150; i++ {
+			log.Error("repeated failure")
+		}
+	})
+	// THEN
+	assert.Len(t, entries, 100)
+}
+
+func TestWithSampling(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithSampling(10, 50), func() {
+		for i := 0; i < 150; i++ {
+			log.Error("repeated failure")
+		}
+	})
+	// THEN
+	assert.Len(t, entries, 12)
+}
+
+func TestWithoutSampling(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithoutSampling(), func() {
+		for i := 0; i < 500; i++ {
+			log.Error("repeated failure")
+		}
+	})
+	// THEN
+	assert.Len(t, entries, 500)
+}
+
+func TestWithLevelSampling(t *testing.T) {
+	// GIVEN
+	config := testConfiguration("INFO").
+		WithSampling(1, 0).
+		WithLevelSampling(zapcore.WarnLevel, 2, 0).
+		WithoutLevelSampling(zapcore.ErrorLevel)
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		for i := 0; i < 5; i++ {
+			log.Info("repeated lookup")
+			log.Warn("repeated retry")
+			log.Error("repeated failure")
+		}
+	})
+	// THEN
+	counts := map[string]int{}
+	for _, entry := range entries {
+		counts[entry[log.Level].(string)]++
+	}
+	assert.Equal(t, map[string]int{"INFO": 1, "WARN": 2, "ERROR": 5}, counts)
+}
+
+func TestWithLevelSamplingAfterWithoutSampling(t *testing.T) {
+	// GIVEN
+	config := testConfiguration("DEBUG").WithoutSampling().WithLevelSampling(zapcore.DebugLevel, 3, 0)
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		for i := 0; i < 200; i++ {
+			log.Debug("repeated lookup")
+			log.Info("repeated request")
+		}
+	})
+	// THEN
+	assert.Len(t, entries, 203)
+}
+
+func TestValidateConfigurationRejectsNegativeLevelSampling(t *testing.T) {
+	// WHEN
+	err := log.ValidateConfiguration(testConfiguration("INFO").WithLevelSampling(zapcore.WarnLevel, -1, 10))
+	// THEN
+	assert.ErrorContains(t, err, "negative WARN sampling -1/10")
+}
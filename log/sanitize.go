@@ -0,0 +1,75 @@
+package log
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"unicode"
+)
+
+// controlCharacterCore escapes the control characters of the message and the string fields of an entry,
+// fields attached through With included, so a value read from a request can't forge a line in a pipeline
+// splitting the output on newlines.
+type controlCharacterCore struct {
+	zapcore.Core
+}
+
+func (c *controlCharacterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &controlCharacterCore{Core: c.Core.With(escapeFields(fields))}
+}
+
+func (c *controlCharacterCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *controlCharacterCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = escapeControlCharacters(entry.Message)
+	return c.Core.Write(entry, escapeFields(fields))
+}
+
+func escapeFields(fields []zapcore.Field) []zapcore.Field {
+	var escaped []zapcore.Field
+	for i, field := range fields {
+		if field.Type != zapcore.StringType || !hasControlCharacters(field.String) {
+			continue
+		}
+		if escaped == nil {
+			escaped = append(make([]zapcore.Field, 0, len(fields)), fields...)
+		}
+		escaped[i] = zap.String(field.Key, escapeControlCharacters(field.String))
+	}
+	if escaped == nil {
+		return fields
+	}
+	return escaped
+}
+
+func hasControlCharacters(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
+
+func escapeControlCharacters(s string) string {
+	if !hasControlCharacters(s) {
+		return s
+	}
+	var escaped strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			escaped.WriteString(`\n`)
+		case r == '\r':
+			escaped.WriteString(`\r`)
+		case r == '\t':
+			escaped.WriteString(`\t`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&escaped, `\u%04x`, r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,49 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const forgedLine = "Booking B-1\n{\"SeverityText\":\"ERROR\",\"Body.message\":\"forged\"}\r\x07"
+
+func TestWithEscapedControlCharacters(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithEscapedControlCharacters(true), func() {
+		log.Info("Loaded %s", forgedLine)
+		log.InfoW("Loaded", "Body.booking.note", forgedLine, "Body.booking.seats", 2)
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	escaped := `Booking B-1\n{"SeverityText":"ERROR","Body.message":"forged"}\r\u0007`
+	assert.Equal(t, "Loaded "+escaped, entries[0][log.Message])
+	assert.Equal(t, escaped, entries[1]["Body.booking.note"])
+	assert.Equal(t, float64(2), entries[1]["Body.booking.seats"])
+}
+
+func TestWithEscapedControlCharactersInConsoleEncoding(t *testing.T) {
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO").WithEncoding(log.EncodingConsole).WithEscapedControlCharacters(true), func() {
+		log.Info(forgedLine)
+	})
+	// THEN
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `Booking B-1\n{"SeverityText":"ERROR"`)
+}
+
+func TestWithEscapedControlCharactersInAttachedFields(t *testing.T) {
+	// GIVEN
+	ctx := log.ContextWith(context.Background(), "Body.booking.source", "web\nforged")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithEscapedControlCharacters(true), func() {
+		log.With("Body.booking.note", forgedLine)
+		log.InfoCtx(ctx, "Loaded")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, `Booking B-1\n{"SeverityText":"ERROR","Body.message":"forged"}\r\u0007`, entries[0]["Body.booking.note"])
+	assert.Equal(t, `web\nforged`, entries[0]["Body.booking.source"])
+}
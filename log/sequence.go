@@ -0,0 +1,13 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync/atomic"
+)
+
+var sequence atomic.Uint64
+
+func sequenceHook(entry zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field) {
+	return entry, append(fields, zap.Uint64(Sequence, sequence.Add(1)))
+}
@@ -0,0 +1,36 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestSequenceIsUniqueAndContiguous(t *testing.T) {
+	// GIVEN
+	config := testConfiguration("INFO").WithSequence(true)
+	// WHEN
+	entries := captureOutput(t, config, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				log.Info("Info msg %d", i)
+			}(i)
+		}
+		wg.Wait()
+	})
+	// THEN
+	assert.Len(t, entries, 50)
+	var seqs []int
+	for _, entry := range entries {
+		seqs = append(seqs, int(entry[log.Sequence].(float64)))
+	}
+	sort.Ints(seqs)
+	for i := 1; i < len(seqs); i++ {
+		assert.Equal(t, seqs[i-1]+1, seqs[i])
+	}
+}
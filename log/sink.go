@@ -0,0 +1,120 @@
+package log
+
+import (
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []*sink
+	// sinksGeneration counts the changes of sinks, for the sinkCores to derive their sink cores again.
+	sinksGeneration uint64
+)
+
+type sink struct {
+	core zapcore.Core
+}
+
+// AddSink mirrors the entries of the package logger into core, e.g. an in-memory buffer dumped when a load test fails,
+// until remove is called. The entries carry the resource fields set by Init and the fields added since, core decides
+// on its own which levels it takes. The sinks survive Init.
+func AddSink(core zapcore.Core) (remove func()) {
+	added := &sink{core: core}
+	sinksMu.Lock()
+	sinks = append(sinks, added)
+	sinksGeneration++
+	sinksMu.Unlock()
+
+	return func() {
+		sinksMu.Lock()
+		defer sinksMu.Unlock()
+		for i, s := range sinks {
+			if s == added {
+				sinks = append(sinks[:i:i], sinks[i+1:]...)
+				sinksGeneration++
+				return
+			}
+		}
+	}
+}
+
+func currentSinks() ([]*sink, uint64) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	return sinks, sinksGeneration
+}
+
+// sinkCore writes the entries to the sinks registered at the time they're written, so AddSink doesn't have to
+// rebuild the package logger and the loggers derived from it. The sink cores with the fields of the logger are
+// derived once per change of the sinks.
+type sinkCore struct {
+	fields []zapcore.Field
+	state  atomic.Pointer[sinkCores]
+}
+
+// sinkCores holds the cores of sinks with the fields of a sinkCore, as of generation.
+type sinkCores struct {
+	generation uint64
+	sinks      []*sink
+	cores      []zapcore.Core
+}
+
+func (c *sinkCore) current() *sinkCores {
+	current, generation := currentSinks()
+	if state := c.state.Load(); state != nil && state.generation == generation {
+		return state
+	}
+	state := &sinkCores{generation: generation, sinks: current, cores: make([]zapcore.Core, len(current))}
+	for i, s := range current {
+		state.cores[i] = s.core
+		if len(c.fields) > 0 {
+			state.cores[i] = s.core.With(c.fields)
+		}
+	}
+	c.state.Store(state)
+	return state
+}
+
+func (c *sinkCore) Enabled(level zapcore.Level) bool {
+	current, _ := currentSinks()
+	for _, s := range current {
+		if s.core.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sinkCore{fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var errs error
+	state := c.current()
+	for i, s := range state.sinks {
+		if s.core.Enabled(entry.Level) {
+			errs = multierr.Append(errs, state.cores[i].Write(entry, fields))
+		}
+	}
+	return errs
+}
+
+func (c *sinkCore) Sync() error {
+	var errs error
+	current, _ := currentSinks()
+	for _, s := range current {
+		errs = multierr.Append(errs, s.core.Sync())
+	}
+	return errs
+}
@@ -0,0 +1,130 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAddSink(t *testing.T) {
+	// GIVEN
+	core, observed := observer.New(zapcore.WarnLevel)
+	var remove func()
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		remove = log.AddSink(core)
+		log.With("Body.booking.id", "B-1")
+		log.Info("Booking loaded")
+		log.WarnW("Booking delayed", "Body.delay.minutes", 30)
+	})
+	remove()
+	log.Warn("Not mirrored")
+	// THEN
+	assert.Len(t, lines, 2)
+	require.Equal(t, 1, observed.Len())
+	entry := observed.All()[0]
+	assert.Equal(t, "Booking delayed", entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, "test-application", fields[log.Application])
+	assert.Equal(t, "B-1", fields["Body.booking.id"])
+	assert.Equal(t, int64(30), fields["Body.delay.minutes"])
+}
+
+func TestAddSinkWithLowerLevel(t *testing.T) {
+	// GIVEN
+	core, observed := observer.New(zapcore.DebugLevel)
+	remove := log.AddSink(core)
+	defer remove()
+	// WHEN
+	lines := captureLines(t, testConfiguration("INFO"), func() {
+		log.Debug("Mirrored only")
+	})
+	// THEN
+	assert.Empty(t, lines)
+	assert.Equal(t, 1, observed.Len())
+}
+
+func TestWithCores(t *testing.T) {
+	// GIVEN
+	firehose, shipped := observer.New(zapcore.InfoLevel)
+	audit, audited := observer.New(zapcore.ErrorLevel)
+	log.SetRedactedKeys([]string{"password"})
+	defer log.SetRedactedKeys(nil)
+	// WHEN
+	lines := captureLines(t, testConfiguration("DEBUG").WithCores(firehose).WithCores(audit), func() {
+		log.Debug("Written only")
+		log.InfoW("Customer created", "password", "hunter2")
+		log.Error("Payment failed")
+	})
+	// THEN
+	assert.Len(t, lines, 3)
+	require.Equal(t, 2, shipped.Len())
+	fields := shipped.All()[0].ContextMap()
+	assert.Equal(t, "test-application", fields[log.Application])
+	assert.Equal(t, log.RedactedValue, fields["password"])
+	require.Equal(t, 1, audited.Len())
+	assert.Equal(t, "Payment failed", audited.All()[0].Message)
+}
+
+func TestValidateConfigurationRejectsNilCore(t *testing.T) {
+	// WHEN
+	err := log.ValidateConfiguration(testConfiguration("INFO").WithCores(nil))
+	// THEN
+	assert.ErrorContains(t, err, "nil core 0")
+}
+
+// countingCore counts the cores derived from it through With.
+type countingCore struct {
+	zapcore.Core
+	withs *atomic.Int32
+}
+
+func (c *countingCore) With(fields []zapcore.Field) zapcore.Core {
+	c.withs.Add(1)
+	return &countingCore{Core: c.Core.With(fields), withs: c.withs}
+}
+
+func (c *countingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func TestAddSinkDerivesSinkCoreOnce(t *testing.T) {
+	// GIVEN
+	observed, logs := observer.New(zapcore.InfoLevel)
+	core := &countingCore{Core: observed, withs: &atomic.Int32{}}
+	// WHEN
+	captureLines(t, testConfiguration("INFO"), func() {
+		remove := log.AddSink(core)
+		defer remove()
+		for i := 0; i < 3; i++ {
+			log.Info("Booking %d loaded", i)
+		}
+	})
+	// THEN
+	assert.Equal(t, 3, logs.Len())
+	assert.Equal(t, int32(1), core.withs.Load())
+}
+
+func TestAddSinkLeavesSequenceContiguous(t *testing.T) {
+	// GIVEN
+	core, observed := observer.New(zapcore.DebugLevel)
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithSequence(true), func() {
+		remove := log.AddSink(core)
+		defer remove()
+		log.Info("First")
+		log.Debug("Sink only")
+		log.Info("Second")
+	})
+	// THEN
+	assert.Equal(t, 3, observed.Len())
+	require.Len(t, entries, 2)
+	assert.Equal(t, entries[0][log.Sequence].(float64)+1, entries[1][log.Sequence])
+}
@@ -0,0 +1,120 @@
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// NewSlogHandler returns a slog.Handler writing through the package logger, so libraries taking an *slog.Logger
+// log in the same schema, with the resource fields and, as LoggerFromContext does, the trace fields of the context
+// passed along with the record. Attributes land under Body., keys already starting with Body. and a root group
+// named Body kept as they are, and groups are flattened into dotted keys, e.g. the method attribute of the http
+// group as Body.http.method.
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{prefix: slogBodyPrefix}
+}
+
+const slogBodyPrefix = "Body."
+
+type slogHandler struct {
+	prefix string
+	fields []zap.Field
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return baseLogger().Desugar().Core().Enabled(zapLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	core := loggerFromContext(ctx).Desugar().Core()
+	entry := zapcore.Entry{
+		Level:   zapLevel(record.Level),
+		Time:    record.Time,
+		Message: record.Message,
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	checked := core.Check(entry, nil)
+	if checked == nil {
+		return nil
+	}
+	fields := append([]zap.Field{}, h.fields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, attr)
+		return true
+	})
+	checked.Write(fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := append([]zap.Field{}, h.fields...)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, h.prefix, attr)
+	}
+	return &slogHandler{prefix: h.prefix, fields: fields}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" || h.prefix == slogBodyPrefix && name+"." == slogBodyPrefix {
+		return h
+	}
+	return &slogHandler{prefix: h.prefix + name + ".", fields: h.fields}
+}
+
+func appendAttr(fields []zap.Field, prefix string, attr slog.Attr) []zap.Field {
+	value := attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	key := prefix + attr.Key
+	if prefix == slogBodyPrefix && strings.HasPrefix(attr.Key, slogBodyPrefix) {
+		key = attr.Key
+	}
+	switch value.Kind() {
+	case slog.KindGroup:
+		groupPrefix := prefix
+		if attr.Key != "" && !(prefix == slogBodyPrefix && attr.Key+"." == slogBodyPrefix) {
+			groupPrefix = key + "."
+		}
+		for _, groupAttr := range value.Group() {
+			fields = appendAttr(fields, groupPrefix, groupAttr)
+		}
+		return fields
+	case slog.KindString:
+		return append(fields, zap.String(key, value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(key, value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(key, value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(key, value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(key, value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(key, value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(key, value.Time()))
+	default:
+		return append(fields, zap.Any(key, value.Any()))
+	}
+}
+
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
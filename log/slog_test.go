@@ -0,0 +1,55 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestNewSlogHandler(t *testing.T) {
+	// GIVEN
+	ctx := spanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		logger := slog.New(log.NewSlogHandler()).With("Body.library", "pricing")
+		logger.Debug("Dropped")
+		logger.WithGroup("http").InfoContext(ctx, "Request sent",
+			"method", "GET",
+			slog.Int("status", 200),
+			slog.Duration("elapsed", 1500*time.Millisecond),
+			slog.Group("retry", slog.Bool("enabled", true)))
+		logger.Error("Request failed", "reason", "timeout")
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	entry := entries[0]
+	assert.Equal(t, "INFO", entry[log.Level])
+	assert.Equal(t, "Request sent", entry[log.Message])
+	assert.Equal(t, "test-application", entry[log.Application])
+	assert.Equal(t, "pricing", entry["Body.library"])
+	assert.Equal(t, "GET", entry["Body.http.method"])
+	assert.Equal(t, float64(200), entry["Body.http.status"])
+	assert.Equal(t, 1.5, entry["Body.http.elapsed"])
+	assert.Equal(t, true, entry["Body.http.retry.enabled"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry[log.TraceId])
+	assert.Equal(t, "log/slog_test.go:19", entry[log.Logger])
+	assert.Equal(t, "ERROR", entries[1][log.Level])
+	assert.Equal(t, "timeout", entries[1]["Body.reason"])
+	assert.NotContains(t, entries[1], log.TraceId)
+}
+
+func TestNewSlogHandlerWithBodyGroup(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		logger := slog.New(log.NewSlogHandler())
+		logger.WithGroup("Body").WithGroup("http").Info("Request sent", "method", "GET")
+		logger.Info("Request retried", slog.Group("Body", slog.Int("attempt", 2)))
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	assert.Equal(t, "GET", entries[0]["Body.http.method"])
+	assert.Equal(t, float64(2), entries[1]["Body.attempt"])
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// TimeEncoding chooses how the Timestamp of the entries is written.
+type TimeEncoding string
+
+const (
+	// TimeEncodingISO8601 writes e.g. 2024-03-01T10:15:30.123Z, the default.
+	TimeEncodingISO8601 TimeEncoding = "iso8601"
+	// TimeEncodingRFC3339Nano writes e.g. 2024-03-01T10:15:30.123456789Z, the default of FormatGCP.
+	TimeEncodingRFC3339Nano TimeEncoding = "rfc3339nano"
+	// TimeEncodingEpochMillis writes the milliseconds elapsed since the Unix epoch as a number.
+	TimeEncodingEpochMillis TimeEncoding = "epochmillis"
+)
+
+// consoleTimeLayout is the short default timestamp of EncodingConsole, the date rarely matters when reading it locally.
+const consoleTimeLayout = "15:04:05.000"
+
+// WithTimeEncoding chooses how the Timestamp of the entries is written, see TimeEncoding.
+func (c Configuration) WithTimeEncoding(encoding TimeEncoding) Configuration {
+	c.timeEncoding = encoding
+	return c
+}
+
+// WithUTC(false) writes the Timestamp of the entries in the local time zone, set through TZ, rather than in UTC.
+func (c Configuration) WithUTC(enabled bool) Configuration {
+	c.localTime = !enabled
+	return c
+}
+
+func timeEncoder(config Configuration) zapcore.TimeEncoder {
+	var encoder zapcore.TimeEncoder
+	switch config.timeEncoding {
+	case TimeEncodingRFC3339Nano:
+		encoder = zapcore.RFC3339NanoTimeEncoder
+	case TimeEncodingEpochMillis:
+		encoder = zapcore.EpochMillisTimeEncoder
+	case TimeEncodingISO8601:
+		encoder = zapcore.ISO8601TimeEncoder
+	default:
+		if config.resolveEncoding() == EncodingConsole {
+			encoder = zapcore.TimeEncoderOfLayout(consoleTimeLayout)
+		} else if config.format == FormatGCP {
+			encoder = zapcore.RFC3339NanoTimeEncoder
+		} else {
+			encoder = zapcore.ISO8601TimeEncoder
+		}
+	}
+	if config.localTime {
+		return encoder
+	}
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		encoder(t.UTC(), enc)
+	}
+}
@@ -0,0 +1,67 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inTimeZone runs the test in the name time zone, as if TZ was set to it.
+func inTimeZone(t *testing.T, name string) {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	local := time.Local
+	time.Local = location
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestTimestampInUTCByDefault(t *testing.T) {
+	// GIVEN
+	inTimeZone(t, "Asia/Tokyo")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.Info("Logged in UTC")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	timestamp := entries[0][log.Timestamp].(string)
+	assert.True(t, strings.HasSuffix(timestamp, "Z"), timestamp)
+	_, err := time.Parse("2006-01-02T15:04:05.000Z0700", timestamp)
+	assert.NoError(t, err)
+}
+
+func TestTimestampInLocalTime(t *testing.T) {
+	// GIVEN
+	inTimeZone(t, "Asia/Tokyo")
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithUTC(false), func() {
+		log.Info("Logged in local time")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.True(t, strings.HasSuffix(entries[0][log.Timestamp].(string), "+0900"), entries[0][log.Timestamp])
+}
+
+func TestWithTimeEncoding(t *testing.T) {
+	// GIVEN
+	before := time.Now()
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithTimeEncoding(log.TimeEncodingEpochMillis), func() {
+		log.Info("Logged in epoch millis")
+	})
+	rfc3339 := captureOutput(t, testConfiguration("INFO").WithTimeEncoding(log.TimeEncodingRFC3339Nano), func() {
+		log.Info("Logged in RFC 3339")
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	millis, ok := entries[0][log.Timestamp].(float64)
+	require.True(t, ok, entries[0][log.Timestamp])
+	assert.InDelta(t, float64(before.UnixMilli()), millis, 5000)
+	require.Len(t, rfc3339, 1)
+	_, err := time.Parse(time.RFC3339Nano, rfc3339[0][log.Timestamp].(string))
+	assert.NoError(t, err)
+}
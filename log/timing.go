@@ -0,0 +1,55 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	SummaryTimings = "Body.summary.timings"
+	SummaryTotal   = "Body.summary.total_ms"
+)
+
+type timingsKey struct{}
+
+type timings struct {
+	mu        sync.Mutex
+	start     time.Time
+	durations map[string]time.Duration
+}
+
+// ContextWithTimings returns a copy of ctx accumulating the sub-operation timings recorded with RecordTiming,
+// the request summary total is measured from this call.
+func ContextWithTimings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timingsKey{}, &timings{start: time.Now(), durations: map[string]time.Duration{}})
+}
+
+// RecordTiming adds d to the time spent on the sub-operation name in ctx, ignored when ctx doesn't come
+// from ContextWithTimings.
+func RecordTiming(ctx context.Context, name string, d time.Duration) {
+	if t, ok := ctx.Value(timingsKey{}).(*timings); ok {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.durations[name] += d
+	}
+}
+
+// LogRequestSummary logs a single line with the sub-operation timings recorded in ctx and the total request time,
+// both in milliseconds.
+func LogRequestSummary(ctx context.Context) {
+	t, ok := ctx.Value(timingsKey{}).(*timings)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	summary := make(map[string]int64, len(t.durations))
+	for name, d := range t.durations {
+		summary[name] = d.Milliseconds()
+	}
+	t.mu.Unlock()
+
+	loggerFromContext(ctx).Infow("Request summary",
+		SummaryTimings, summary,
+		SummaryTotal, time.Since(t.start).Milliseconds())
+}
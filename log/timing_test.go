@@ -0,0 +1,37 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLogRequestSummary(t *testing.T) {
+	// GIVEN
+	ctx := log.ContextWithTimings(context.Background())
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.RecordTiming(ctx, "dynamodb", 40*time.Millisecond)
+		log.RecordTiming(ctx, "pricing", 15*time.Millisecond)
+		log.RecordTiming(ctx, "dynamodb", 20*time.Millisecond)
+		log.LogRequestSummary(ctx)
+	})
+	// THEN
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Request summary", entries[0][log.Message])
+	assert.Equal(t, map[string]interface{}{"dynamodb": float64(60), "pricing": float64(15)}, entries[0][log.SummaryTimings])
+	assert.Contains(t, entries[0], log.SummaryTotal)
+}
+
+func TestLogRequestSummaryWithoutTimings(t *testing.T) {
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO"), func() {
+		log.RecordTiming(context.Background(), "dynamodb", time.Millisecond)
+		log.LogRequestSummary(context.Background())
+	})
+	// THEN
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ValidateConfiguration runs the checks Init would run on config, opening the outputs included, without installing
+// a logger, so a service can fail fast at boot instead of logging with fallbacks. The outputs are closed again
+// and the files opening them created are removed.
+func ValidateConfiguration(config Configuration) error {
+	var errs error
+	if _, err := parseLevel(config.logLevel); err != nil {
+		errs = malformedLevelError(config.logLevel)
+	}
+	errs = multierr.Append(errs, checkConfiguration(config))
+	if err := validateOutputPaths(config); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+	if errs != nil {
+		return errs
+	}
+
+	outputPaths, errorOutputPaths := outputPathsOf(config)
+	if err := checkOutputs(append(outputPaths, errorOutputPaths...)); err != nil {
+		return fmt.Errorf("building logger: %w", err)
+	}
+	return nil
+}
+
+// checkOutputs opens paths the way zap does and closes them, removing the files it created.
+func checkOutputs(paths []string) error {
+	var created []string
+	for _, path := range paths {
+		if file := filePath(path); file != "" {
+			if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+				created = append(created, file)
+			}
+		}
+	}
+	_, closeOutputs, err := zap.Open(paths...)
+	if err == nil {
+		closeOutputs()
+	}
+	for _, file := range created {
+		_ = os.Remove(file)
+	}
+	return err
+}
+
+// filePath returns the file path zap opens for path, empty for the standard streams and the other sinks.
+func filePath(path string) string {
+	switch {
+	case path == Stdout || path == Stderr:
+		return ""
+	case !strings.Contains(path, "://"):
+		return path
+	}
+	if u, err := url.Parse(path); err == nil && u.Scheme == "file" {
+		return u.Path
+	}
+	return ""
+}
+
+// checkConfiguration reports the settings Init would silently replace with defaults.
+func checkConfiguration(config Configuration) error {
+	var errs error
+	if config.format != FormatDefault && config.format != FormatGCP {
+		errs = multierr.Append(errs, fmt.Errorf("unknown log format %q", config.format))
+	}
+	if encoding := config.resolveEncoding(); encoding != EncodingJSON && encoding != EncodingConsole {
+		errs = multierr.Append(errs, fmt.Errorf("unknown log encoding %q", encoding))
+	}
+	switch config.timeEncoding {
+	case "", TimeEncodingISO8601, TimeEncodingRFC3339Nano, TimeEncodingEpochMillis:
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("unknown time encoding %q", config.timeEncoding))
+	}
+	if config.marshalFailureMode < MarshalFailureDefault || config.marshalFailureMode > MarshalFailureWarn {
+		errs = multierr.Append(errs, fmt.Errorf("unknown marshal failure mode %d", config.marshalFailureMode))
+	}
+	if config.maxCustomAttributes < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("negative custom attributes limit %d", config.maxCustomAttributes))
+	}
+	if s := config.sampling; s != nil && (s.Initial < 0 || s.Thereafter < 0) {
+		errs = multierr.Append(errs, fmt.Errorf("negative sampling %d/%d", s.Initial, s.Thereafter))
+	}
+	for l, s := range config.levelSampling {
+		if s != nil && (s.Initial < 0 || s.Thereafter < 0) {
+			errs = multierr.Append(errs, fmt.Errorf("negative %s sampling %d/%d", l.CapitalString(), s.Initial, s.Thereafter))
+		}
+	}
+	if config.callerSkip < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("negative caller skip %d", config.callerSkip))
+	}
+	for i, core := range config.cores {
+		if core == nil {
+			errs = multierr.Append(errs, fmt.Errorf("nil core %d", i))
+		}
+	}
+	for route, core := range config.routes {
+		if core == nil {
+			errs = multierr.Append(errs, fmt.Errorf("nil core for route %q", route))
+		}
+	}
+	return errs
+}
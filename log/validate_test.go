@@ -0,0 +1,65 @@
+package log_test
+
+import (
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateConfiguration(t *testing.T) {
+	assert.NoError(t, log.ValidateConfiguration(testConfiguration("DEBUG")))
+	assert.NoError(t, log.ValidateConfiguration(testConfiguration("warn").WithFormat(log.FormatGCP)))
+}
+
+func TestValidateConfigurationRejectsInvalid(t *testing.T) {
+	tests := map[string]log.Configuration{
+		"malformed level":         testConfiguration("VERBOSE"),
+		"unknown format":          testConfiguration("INFO").WithFormat("xml"),
+		"unknown encoding":        testConfiguration("INFO").WithEncoding("yaml"),
+		"unknown time encoding":   testConfiguration("INFO").WithTimeEncoding("unix"),
+		"negative caller skip":    testConfiguration("INFO").WithCallerSkip(-1),
+		"negative sampling":       testConfiguration("INFO").WithSampling(-1, 100),
+		"unknown marshal mode":    testConfiguration("INFO").WithMarshalFailureMode(42),
+		"negative attributes cap": testConfiguration("INFO").WithMaxCustomAttributes(-1),
+		"nil route core":          testConfiguration("INFO").WithRouting("Body.audit", map[string]zapcore.Core{"yes": nil}),
+		"missing output dir":      testConfiguration("INFO").WithOutputPaths(filepath.Join(t.TempDir(), "missing", "app.log")),
+		"empty output path":       testConfiguration("INFO").WithOutputPaths(log.Stdout, ""),
+		"missing error output":    testConfiguration("INFO").WithErrorOutputPaths(filepath.Join(t.TempDir(), "missing", "err.log")),
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, log.ValidateConfiguration(config))
+		})
+	}
+}
+
+func TestValidateConfigurationReportsAllProblems(t *testing.T) {
+	// WHEN
+	err := log.ValidateConfiguration(testConfiguration("VERBOSE").WithFormat("xml"))
+	// THEN
+	assert.ErrorContains(t, err, "malformed log level")
+	assert.ErrorContains(t, err, "unknown log format")
+}
+
+func TestValidateConfigurationLeavesNoFiles(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.log")
+	require.NoError(t, os.WriteFile(existing, []byte("kept\n"), 0o644))
+	config := testConfiguration("INFO").
+		WithOutputPaths(filepath.Join(dir, "app.log"), existing).
+		WithErrorOutputPaths("file://" + filepath.Join(dir, "err.log"))
+	// WHEN
+	err := log.ValidateConfiguration(config)
+	// THEN
+	assert.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "app.log"))
+	assert.NoFileExists(t, filepath.Join(dir, "err.log"))
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "kept\n", string(content))
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+)
+
+// LevelSource reads the wanted log level, e.g. from an SSM parameter.
+type LevelSource func(ctx context.Context) (string, error)
+
+// EnvLevelSource reads the level from the environment variable name.
+func EnvLevelSource(name string) LevelSource {
+	return func(context.Context) (string, error) {
+		return os.Getenv(name), nil
+	}
+}
+
+type levelWatcher struct {
+	source      LevelSource
+	every       uint64
+	invocations atomic.Uint64
+}
+
+var watcher atomic.Pointer[levelWatcher]
+
+// WatchLevel makes SetupLambdaContext re-read the level from source every n invocations and apply it
+// through SetLevel, so DEBUG can be turned on for a running Lambda without redeploying. A nil source reads LOG_LEVEL,
+// an empty level leaves the current one in place. The source runs on the invocation, keep it fast. The level is only
+// refreshed for the handlers calling SetupLambdaContext, as those wrapped with frotel.WrapHandler do.
+func WatchLevel(source LevelSource, every int) (stop func()) {
+	if source == nil {
+		source = EnvLevelSource("LOG_LEVEL")
+	}
+	if every < 1 {
+		every = 1
+	}
+	w := &levelWatcher{source: source, every: uint64(every)}
+	watcher.Store(w)
+	return func() {
+		watcher.CompareAndSwap(w, nil)
+	}
+}
+
+func refreshLevel(ctx context.Context) {
+	w := watcher.Load()
+	if w == nil || w.invocations.Add(1)%w.every != 0 {
+		return
+	}
+	logLevel, err := w.source(ctx)
+	if err == nil && logLevel != "" {
+		err = SetLevel(logLevel)
+	}
+	if err != nil {
+		logger().Warnf("log level not refreshed, %v", err)
+	}
+}
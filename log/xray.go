@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-xray-sdk-go/strategy/ctxmissing"
 	"github.com/aws/aws-xray-sdk-go/xray"
 	"github.com/aws/aws-xray-sdk-go/xraylog"
+	"go.uber.org/zap"
 )
 
 type xRayLogger struct {
@@ -15,13 +16,16 @@ type xRayLogger struct {
 func (x *xRayLogger) Log(level xraylog.LogLevel, msg fmt.Stringer) {
 	switch level {
 	case xraylog.LogLevelWarn:
-		log.Warn(msg.String())
+		logger().Warn(msg.String())
 	case xraylog.LogLevelError:
-		log.Error(msg.String())
+		logger().Error(msg.String())
 	}
 }
 
-func setUpXRay() {
+// xraySetup is replaced by the tests checking whether Init configures X-Ray.
+var xraySetup = setUpXRay
+
+func setUpXRay(log *zap.SugaredLogger) {
 	if err := xray.Configure(xray.Config{ContextMissingStrategy: &ctxmissing.DefaultIgnoreErrorStrategy{}}); err != nil {
 		log.Error("unable to configure xray: %+v", err)
 	}
@@ -32,7 +36,19 @@ func setupXRayLogger() {
 	xray.SetLogger(&xRayLogger{})
 }
 
+// TraceHeaderFromContext returns the X-Ray trace header of ctx, nil when there's none or X-Ray is disabled
+// with WithXRay(false).
+func TraceHeaderFromContext(ctx context.Context) *header.Header {
+	return getTraceHeaderFromContext(ctx)
+}
+
 func getTraceHeaderFromContext(ctx context.Context) *header.Header {
+	logMu.RLock()
+	disabled := logConfig.xrayDisabled
+	logMu.RUnlock()
+	if disabled {
+		return nil
+	}
 	var traceHeader string
 
 	if traceHeaderValue := ctx.Value(xray.LambdaTraceHeaderKey); traceHeaderValue != nil {
@@ -0,0 +1,76 @@
+package log_test
+
+import (
+	"context"
+	"github.com/Ryanair/gofrlib/log"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestToW3CWithError(t *testing.T) {
+	// WHEN
+	traceId, err := log.ToW3CWithError("1-5759e988-bd862e3fe1be46a994272793")
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", traceId)
+}
+
+func TestToW3CWithErrorRejectsMalformedIds(t *testing.T) {
+	tests := map[string]string{
+		"wrong part count":   "1-5759e988bd862e3fe1be46a994272793",
+		"non hex epoch":      "1-5759e98z-bd862e3fe1be46a994272793",
+		"non hex unique":     "1-5759e988-bd862e3fe1be46a99427279x",
+		"short unique":       "1-5759e988-bd862e3fe1be46a9",
+		"not an X-Ray trace": "TraceIdValue",
+	}
+	for name, xrayTraceID := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := log.ToW3CWithError(xrayTraceID)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestToW3CReturnsMalformedIdUnchanged(t *testing.T) {
+	log.ResetLogger()
+	assert.Equal(t, "TraceIdValue", log.ToW3C("TraceIdValue"))
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", log.ToW3C("1-5759e988-bd862e3fe1be46a994272793"))
+}
+
+func TestInitWithoutXRay(t *testing.T) {
+	// GIVEN
+	count, restore := log.CountXRaySetups()
+	defer restore()
+	// WHEN
+	log.Init(testConfiguration("INFO").WithXRay(false))
+	// THEN
+	assert.Equal(t, 0, count())
+	log.Init(testConfiguration("INFO"))
+	assert.Equal(t, 1, count())
+}
+
+func TestSetupTraceIdsWithoutXRayIgnoresTraceHeader(t *testing.T) {
+	// GIVEN
+	headerCtx := context.WithValue(context.Background(), xray.LambdaTraceHeaderKey,
+		"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId,
+		SpanID:  trace.SpanID{0x0f},
+	}))
+	// WHEN
+	entries := captureOutput(t, testConfiguration("INFO").WithXRay(false), func() {
+		log.SetupTraceIds(headerCtx)
+		log.Info("Without trace")
+		log.SetupTraceIds(spanCtx)
+		log.Info("With trace")
+		log.ResetRequestFields()
+	})
+	// THEN
+	require.Len(t, entries, 2)
+	assert.NotContains(t, entries[0], log.TraceId)
+	assert.Equal(t, traceId.String(), entries[1][log.TraceId])
+}